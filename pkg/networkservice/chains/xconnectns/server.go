@@ -34,6 +34,7 @@ import (
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms"
 
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/stablemac"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/metrics"
 
@@ -84,6 +85,8 @@ func NewServer(ctx context.Context, name string, authzServer networkservice.Netw
 			vxlan.MECHANISM:  vxlan.NewServer(tunnelIP, vxlanInitFunc),
 			srv6.MECHANISM:   srv6.NewServer(),
 		}),
+		// Stable mac addresses for the interfaces of the incoming connection, known before they are created
+		stablemac.NewServer(),
 		// Statically set the url we use to the unix file socket for the NSMgr
 		clienturl.NewServer(clientURL),
 		connect.NewServer(
@@ -94,11 +97,14 @@ func NewServer(ctx context.Context, name string, authzServer networkservice.Netw
 				addressof.NetworkServiceClient(adapters.NewServerToClient(rv)),
 				tokenGenerator,
 				connectioncontextkernel.NewClient(),
+				stablemac.NewClient(),
 				// Preference ordered list of mechanisms we support for outgoing connections
 				memif.NewClient(),
 				kernel.NewClient(),
 				vxlan.NewClient(tunnelIP, vxlanInitFunc),
 				srv6.NewClient(),
+				// Receives the files passed by file descriptor, such as memif socket directories, before the
+				// mechanism clients above get to see the response
 				recvfd.NewClient()),
 			clientDialOptions...,
 		),
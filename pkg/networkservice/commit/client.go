@@ -21,6 +21,7 @@ package commit
 import (
 	"context"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
 	"github.com/pkg/errors"
@@ -72,6 +73,11 @@ func (c *commitClient) Request(ctx context.Context, request *networkservice.Netw
 		}
 		break
 	}
+	if stale := vppagent.StaleConfig(ctx); stale != nil && proto.Size(stale) > 0 {
+		if _, err = c.vppagentClient.Delete(ctx, &configurator.DeleteRequest{Delete: stale}); err != nil {
+			return nil, errors.Wrapf(err, "error deleting stale config from vppagent %s: ", stale)
+		}
+	}
 
 	return rv, nil
 }
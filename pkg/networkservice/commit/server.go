@@ -20,6 +20,7 @@ import (
 	"context"
 	"sync"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/pkg/errors"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
@@ -58,6 +59,11 @@ func (c *commitServer) Request(ctx context.Context, request *networkservice.Netw
 	if err != nil {
 		return nil, errors.Wrapf(err, "error sending config to vppagent %s: ", conf)
 	}
+	if stale := vppagent.StaleConfig(ctx); stale != nil && proto.Size(stale) > 0 {
+		if _, err = c.vppagentClient.Delete(ctx, &configurator.DeleteRequest{Delete: stale}); err != nil {
+			return nil, errors.Wrapf(err, "error deleting stale config from vppagent %s: ", stale)
+		}
+	}
 	return next.Server(ctx).Request(ctx, request)
 }
 
@@ -23,6 +23,7 @@ import (
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/arps"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/macaddress"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ipcontext/ipaddress"
 )
@@ -53,6 +54,7 @@ func NewClient() networkservice.NetworkServiceClient {
 	return chain.NewNetworkServiceClient(
 		ipaddress.NewClient(),
 		macaddress.NewClient(),
+		arps.NewClient(),
 		routes.NewClient(),
 	)
 }
@@ -0,0 +1,54 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arps
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+type setArpsVppClient struct{}
+
+// NewClient creates a NetworkServiceClient chain element to set the arp entry of the DstIpAddr on a vpp interface
+// It sets the arp entry on the *vpp* side of an interface leaving the
+// Endpoint, so vpp doesn't need to arp for the Endpoint.
+func NewClient() networkservice.NetworkServiceClient {
+	return &setArpsVppClient{}
+}
+
+func (s *setArpsVppClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	appendArpEntry(vppagent.Config(ctx), conn.GetContext().GetIpContext().GetDstIpAddr(), conn.GetContext().GetEthernetContext().GetDstMac())
+	return conn, nil
+}
+
+func (s *setArpsVppClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	e, err := next.Client(ctx).Close(ctx, conn, opts...)
+	appendArpEntry(vppagent.Config(ctx), conn.GetContext().GetIpContext().GetDstIpAddr(), conn.GetContext().GetEthernetContext().GetDstMac())
+	return e, err
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arps_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	memif_mechanisms "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/arps"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const (
+	MacAddress     = "00:1B:44:11:3A:B7"
+	SocketFilename = "socketfilename"
+)
+
+func TestSetArpsVppClient(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id:        "id",
+			Mechanism: memif_mechanisms.New(SocketFilename),
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					DstIpAddr: "fd00::2/127",
+				},
+				EthernetContext: &networkservice.EthernetContext{
+					DstMac: MacAddress,
+				},
+			},
+		},
+	}
+	client := chain.NewNetworkServiceClient(
+		arps.NewClient(),
+		memif.NewClient(),
+	)
+	expected := []*vpp.ARPEntry{
+		{Interface: "client-id", IpAddress: "fd00::2", PhysAddress: MacAddress, Static: true},
+	}
+
+	ctx := vppagent.WithConfig(context.Background())
+	conn, err := client.Request(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, expected, vppagent.Config(ctx).GetVppConfig().GetArps())
+
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = client.Close(ctx, conn)
+	require.NoError(t, err)
+	assert.Equal(t, expected, vppagent.Config(ctx).GetVppConfig().GetArps())
+}
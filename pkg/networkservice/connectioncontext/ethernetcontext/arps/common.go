@@ -0,0 +1,49 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package arps provides networkservice chain elements for setting the arp entries (the neighbor entries for IPv6) of
+// the peer on vpp interfaces
+package arps
+
+import (
+	"net"
+
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+)
+
+// appendArpEntry - appends the static arp entry of the peer's ipAddr and hwAddr on the last vpp interface, unless either
+//                  address is missing or ipAddr is not an IP address, with or without its prefix length
+func appendArpEntry(conf *configurator.Config, ipAddr, hwAddr string) {
+	index := len(conf.GetVppConfig().GetInterfaces()) - 1
+	ip := parseIP(ipAddr)
+	if index < 0 || ip == nil || hwAddr == "" {
+		return
+	}
+	conf.GetVppConfig().Arps = append(conf.GetVppConfig().GetArps(), &vpp.ARPEntry{
+		Interface:   conf.GetVppConfig().GetInterfaces()[index].GetName(),
+		IpAddress:   ip.String(),
+		PhysAddress: hwAddr,
+		Static:      true,
+	})
+}
+
+func parseIP(ipAddr string) net.IP {
+	if ip, _, err := net.ParseCIDR(ipAddr); err == nil {
+		return ip
+	}
+	return net.ParseIP(ipAddr)
+}
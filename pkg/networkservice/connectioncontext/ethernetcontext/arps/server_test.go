@@ -0,0 +1,92 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arps_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	memif_mechanisms "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/arps"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+func TestSetArpsVppServer(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "id",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: memif_mechanisms.MECHANISM,
+			},
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					SrcIpAddr: "172.16.1.1/31",
+				},
+				EthernetContext: &networkservice.EthernetContext{
+					SrcMac: MacAddress,
+				},
+			},
+		},
+	}
+	committed := &commitServer{}
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(t.TempDir()),
+		arps.NewServer(),
+		committed,
+	)
+	expected := []*vpp.ARPEntry{
+		{Interface: "server-id", IpAddress: "172.16.1.1", PhysAddress: MacAddress, Static: true},
+	}
+
+	conn, err := server.Request(vppagent.WithConfig(context.Background()), request)
+	require.NoError(t, err)
+	assert.Equal(t, expected, committed.arps)
+
+	committed.arps = nil
+	_, err = server.Close(vppagent.WithConfig(context.Background()), conn)
+	require.NoError(t, err)
+	assert.Equal(t, expected, committed.arps)
+}
+
+// commitServer - keeps the vpp arp entries of the config as it is at the end of the chain, where it is committed
+type commitServer struct {
+	arps []*vpp.ARPEntry
+}
+
+func (c *commitServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	c.arps = vppagent.Config(ctx).GetVppConfig().GetArps()
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (c *commitServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	c.arps = vppagent.Config(ctx).GetVppConfig().GetArps()
+	return next.Server(ctx).Close(ctx, conn)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stablemac
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/macaddr"
+)
+
+type stableMacClient struct{}
+
+// NewClient creates a NetworkServiceClient chain element assigning the SrcMac of the connection from its ID, where the
+// EthernetContext doesn't have it yet.  The DstMac is left to the Endpoint and taken from the connection it returns.
+// The kernel interface, or else the vpp interface, leaving the Client gets the SrcMac and the vpp side of a kernel
+// interface gets the DstMac.
+// It must precede the mechanism and follow the connectioncontext chain elements, so arps can use the mac addresses.
+func NewClient() networkservice.NetworkServiceClient {
+	return &stableMacClient{}
+}
+
+func (s *stableMacClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	fillEthernetContext(request.GetConnection(), macaddr.Src)
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.setPhysAddresses(ctx, conn)
+	return conn, nil
+}
+
+func (s *stableMacClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.setPhysAddresses(ctx, conn)
+	return rv, nil
+}
+
+func (s *stableMacClient) setPhysAddresses(ctx context.Context, conn *networkservice.Connection) {
+	conf := vppagent.Config(ctx)
+	var linuxIface *linux.Interface
+	if linuxIfaces := conf.GetLinuxConfig().GetInterfaces(); kernel.ToMechanism(conn.GetMechanism()) != nil && len(linuxIfaces) > 0 {
+		linuxIface = linuxIfaces[len(linuxIfaces)-1]
+	}
+	ethernetContext := conn.GetContext().GetEthernetContext()
+	setPhysAddresses(conf, conn, linuxIface, ethernetContext.GetSrcMac(), ethernetContext.GetDstMac())
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stablemac_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	memif_mechanisms "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+	linuxinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/linux/interfaces"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/stablemac"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/macaddr"
+)
+
+func TestStableMacClient(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id:        "id",
+			Mechanism: memif_mechanisms.New("socketfilename"),
+		},
+	}
+	client := chain.NewNetworkServiceClient(
+		stablemac.NewClient(),
+		memif.NewClient(),
+	)
+	srcMac := macaddr.Generate("id", macaddr.Src)
+
+	ctx := vppagent.WithConfig(context.Background())
+	conn, err := client.Request(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, srcMac, conn.GetContext().GetEthernetContext().GetSrcMac())
+	// The DstMac is left to the Endpoint
+	assert.Empty(t, conn.GetContext().GetEthernetContext().GetDstMac())
+	assert.Equal(t, srcMac, vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetPhysAddress())
+
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = client.Close(ctx, conn)
+	require.NoError(t, err)
+	assert.Equal(t, srcMac, vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetPhysAddress())
+}
+
+// endpointClient - returns the connection with the DstMac set by the Endpoint
+type endpointClient struct {
+	dstMac string
+}
+
+func (c *endpointClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn := request.GetConnection()
+	conn.GetContext().GetEthernetContext().DstMac = c.dstMac
+	return conn, nil
+}
+
+func (c *endpointClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return &empty.Empty{}, nil
+}
+
+// tapClient - appends the kernel interface and its vpp side, as the kerneltap client does
+type tapClient struct{}
+
+func (c *tapClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conf := vppagent.Config(ctx)
+	conf.GetVppConfig().Interfaces = append(conf.GetVppConfig().Interfaces, &vpp.Interface{Type: vppinterfaces.Interface_TAP})
+	conf.GetLinuxConfig().Interfaces = append(conf.GetLinuxConfig().Interfaces, &linux.Interface{Type: linuxinterfaces.Interface_TAP_TO_VPP})
+	return next.Client(ctx).Request(ctx, request, opts...)
+}
+
+func (c *tapClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
+
+func TestStableMacClient_Kernel(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "id",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: kernel.MECHANISM,
+			},
+		},
+	}
+	client := chain.NewNetworkServiceClient(
+		stablemac.NewClient(),
+		&tapClient{},
+		&endpointClient{dstMac: "02:00:00:00:00:02"},
+	)
+	ctx := vppagent.WithConfig(context.Background())
+	conn, err := client.Request(ctx, request)
+	require.NoError(t, err)
+
+	// The kernel interface gets the SrcMac, its vpp side the DstMac of the Endpoint
+	srcMac := macaddr.Generate("id", macaddr.Src)
+	assert.Equal(t, srcMac, conn.GetContext().GetEthernetContext().GetSrcMac())
+	assert.Equal(t, "02:00:00:00:00:02", conn.GetContext().GetEthernetContext().GetDstMac())
+	assert.Equal(t, srcMac, vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()[0].GetPhysAddress())
+	assert.Equal(t, "02:00:00:00:00:02", vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetPhysAddress())
+}
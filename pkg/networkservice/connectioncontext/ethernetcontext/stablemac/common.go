@@ -0,0 +1,85 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stablemac provides networkservice chain elements assigning stable mac addresses to the interfaces of
+// connections, so the mac addresses are known before the interfaces are created and survive healing
+package stablemac
+
+import (
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+	linuxinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/linux/interfaces"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/macaddr"
+)
+
+// fillEthernetContext - sets the mac addresses of sides of the EthernetContext of conn that are not set yet
+func fillEthernetContext(conn *networkservice.Connection, sides ...string) {
+	ethernetContext := macaddr.EthernetContext(conn)
+	for _, side := range sides {
+		switch {
+		case side == macaddr.Src && ethernetContext.GetSrcMac() == "":
+			ethernetContext.SrcMac = macaddr.Generate(conn.GetId(), macaddr.Src)
+		case side == macaddr.Dst && ethernetContext.GetDstMac() == "":
+			ethernetContext.DstMac = macaddr.Generate(conn.GetId(), macaddr.Dst)
+		}
+	}
+}
+
+// setPhysAddresses - sets the mac addresses on the ethernet interfaces of conn that have none:
+//                    linuxIface - the kernel interface gets ifaceMac
+//                    last vpp interface - the vpp interface gets ifaceMac, unless it is the vpp side of the kernel
+//                                         interface, which gets peerMac
+//                    An empty mac address leaves the interface to the default of the kernel or vpp.
+func setPhysAddresses(conf *configurator.Config, conn *networkservice.Connection, linuxIface *linux.Interface, ifaceMac, peerMac string) {
+	if linuxIface != nil && isLinuxEthernet(linuxIface) && linuxIface.GetPhysAddress() == "" {
+		linuxIface.PhysAddress = ifaceMac
+	}
+	index := len(conf.GetVppConfig().GetInterfaces()) - 1
+	if index < 0 {
+		return
+	}
+	vppIface := conf.GetVppConfig().GetInterfaces()[index]
+	if !isVppEthernet(vppIface) || vppIface.GetPhysAddress() != "" {
+		return
+	}
+	vppIface.PhysAddress = ifaceMac
+	if kernel.ToMechanism(conn.GetMechanism()) != nil {
+		vppIface.PhysAddress = peerMac
+	}
+}
+
+// isLinuxEthernet - returns true for the kernel interfaces carrying ethernet frames
+func isLinuxEthernet(iface *linux.Interface) bool {
+	return iface.GetType() == linuxinterfaces.Interface_TAP_TO_VPP || iface.GetType() == linuxinterfaces.Interface_VETH
+}
+
+// isVppEthernet - returns true for the vpp interfaces carrying ethernet frames, leaving out the ip tunnels (gre, ipip,
+//                 ...) and the memifs in ip mode, which have no use for a mac address
+func isVppEthernet(iface *vpp.Interface) bool {
+	switch iface.GetType() {
+	case vppinterfaces.Interface_TAP, vppinterfaces.Interface_AF_PACKET, vppinterfaces.Interface_VXLAN_TUNNEL:
+		return true
+	case vppinterfaces.Interface_MEMIF:
+		return iface.GetMemif().GetMode() == vppinterfaces.MemifLink_ETHERNET
+	}
+	return false
+}
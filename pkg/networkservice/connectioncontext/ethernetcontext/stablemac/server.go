@@ -0,0 +1,54 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stablemac
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/macaddr"
+)
+
+type stableMacServer struct{}
+
+// NewServer creates a NetworkServiceServer chain element assigning the mac addresses of the connection from its
+// ID, where the EthernetContext doesn't have them yet.  The kernel interface, or else the vpp interface, plugged into
+// the Endpoint gets the DstMac and the vpp side of a kernel interface gets the SrcMac.
+// It must follow the mechanism and precede the connectioncontext chain elements, so arps can use the mac addresses.
+func NewServer() networkservice.NetworkServiceServer {
+	return &stableMacServer{}
+}
+
+func (s *stableMacServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	conn := request.GetConnection()
+	fillEthernetContext(conn, macaddr.Src, macaddr.Dst)
+	ethernetContext := conn.GetContext().GetEthernetContext()
+	setPhysAddresses(vppagent.Config(ctx), conn, kernelctx.ServerInterface(ctx), ethernetContext.GetDstMac(), ethernetContext.GetSrcMac())
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (s *stableMacServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	ethernetContext := conn.GetContext().GetEthernetContext()
+	setPhysAddresses(vppagent.Config(ctx), conn, kernelctx.ServerInterface(ctx), ethernetContext.GetDstMac(), ethernetContext.GetSrcMac())
+	return next.Server(ctx).Close(ctx, conn)
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stablemac_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	memif_mechanisms "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/stablemac"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/macaddr"
+)
+
+const MacAddress = "0a:1b:3c:4d:5e:6f"
+
+func TestStableMacServer(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "id",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: memif_mechanisms.MECHANISM,
+			},
+		},
+	}
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(t.TempDir()),
+		stablemac.NewServer(),
+	)
+	srcMac := macaddr.Generate("id", macaddr.Src)
+	dstMac := macaddr.Generate("id", macaddr.Dst)
+
+	ctx := vppagent.WithConfig(context.Background())
+	conn, err := server.Request(ctx, request.Clone())
+	require.NoError(t, err)
+	assert.Equal(t, srcMac, conn.GetContext().GetEthernetContext().GetSrcMac())
+	assert.Equal(t, dstMac, conn.GetContext().GetEthernetContext().GetDstMac())
+	assert.Equal(t, dstMac, vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetPhysAddress())
+
+	// Healing gets the same mac addresses
+	ctx = vppagent.WithConfig(context.Background())
+	healed, err := server.Request(ctx, request.Clone())
+	require.NoError(t, err)
+	assert.Equal(t, conn.GetContext().GetEthernetContext(), healed.GetContext().GetEthernetContext())
+	assert.Equal(t, dstMac, vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetPhysAddress())
+
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx, conn)
+	require.NoError(t, err)
+	assert.Equal(t, dstMac, vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetPhysAddress())
+}
+
+func TestStableMacServer_EthernetContext(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "id",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: memif_mechanisms.MECHANISM,
+			},
+			Context: &networkservice.ConnectionContext{
+				EthernetContext: &networkservice.EthernetContext{
+					DstMac: MacAddress,
+				},
+			},
+		},
+	}
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(t.TempDir()),
+		stablemac.NewServer(),
+	)
+
+	// The mac addresses already in the EthernetContext are kept
+	ctx := vppagent.WithConfig(context.Background())
+	conn, err := server.Request(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, macaddr.Generate("id", macaddr.Src), conn.GetContext().GetEthernetContext().GetSrcMac())
+	assert.Equal(t, MacAddress, conn.GetContext().GetEthernetContext().GetDstMac())
+	assert.Equal(t, MacAddress, vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetPhysAddress())
+}
+
+func TestStableMacServer_IPMode(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "id",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: memif_mechanisms.MECHANISM,
+			},
+		},
+	}
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(t.TempDir(), memif.WithIPMode()),
+		stablemac.NewServer(),
+	)
+
+	// A memif carrying IP packets has no mac address
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := server.Request(ctx, request)
+	require.NoError(t, err)
+	assert.Empty(t, vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetPhysAddress())
+}
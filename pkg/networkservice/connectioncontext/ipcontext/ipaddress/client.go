@@ -26,6 +26,7 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 )
@@ -65,7 +66,7 @@ func (s *setVppIPClient) Request(ctx context.Context, request *networkservice.Ne
 	}
 	conf := vppagent.Config(ctx)
 	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 && conn.GetContext().GetIpContext().GetSrcIpAddr() != "" {
-		conf.GetVppConfig().GetInterfaces()[index].IpAddresses = []string{conn.GetContext().GetIpContext().GetSrcIpAddr()}
+		conf.GetVppConfig().GetInterfaces()[index].IpAddresses = ipaddrs.Append(ctx, conf.GetVppConfig().GetInterfaces()[index].GetIpAddresses(), conn.GetContext().GetIpContext().GetSrcIpAddr())
 	}
 	return conn, nil
 }
@@ -74,7 +75,7 @@ func (s *setVppIPClient) Close(ctx context.Context, conn *networkservice.Connect
 	e, err := next.Client(ctx).Close(ctx, conn, opts...)
 	conf := vppagent.Config(ctx)
 	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 && conn.GetContext().GetIpContext().GetSrcIpAddr() != "" {
-		conf.GetVppConfig().GetInterfaces()[index].IpAddresses = []string{conn.GetContext().GetIpContext().GetSrcIpAddr()}
+		conf.GetVppConfig().GetInterfaces()[index].IpAddresses = ipaddrs.Append(ctx, conf.GetVppConfig().GetInterfaces()[index].GetIpAddresses(), conn.GetContext().GetIpContext().GetSrcIpAddr())
 	}
 	return e, err
 }
@@ -25,6 +25,7 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
 )
 
 type setVppIPServer struct{}
@@ -60,7 +61,7 @@ func (s *setVppIPServer) Request(ctx context.Context, request *networkservice.Ne
 	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 {
 		dstIP := request.GetConnection().GetContext().GetIpContext().GetDstIpAddr()
 		if dstIP != "" {
-			conf.GetVppConfig().GetInterfaces()[index].IpAddresses = []string{dstIP}
+			conf.GetVppConfig().GetInterfaces()[index].IpAddresses = ipaddrs.Append(ctx, conf.GetVppConfig().GetInterfaces()[index].GetIpAddresses(), dstIP)
 		}
 	}
 	return next.Server(ctx).Request(ctx, request)
@@ -71,7 +72,7 @@ func (s *setVppIPServer) Close(ctx context.Context, conn *networkservice.Connect
 	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 {
 		dstIP := conn.GetContext().GetIpContext().GetDstIpAddr()
 		if dstIP != "" {
-			conf.GetVppConfig().GetInterfaces()[index].IpAddresses = []string{dstIP}
+			conf.GetVppConfig().GetInterfaces()[index].IpAddresses = ipaddrs.Append(ctx, conf.GetVppConfig().GetInterfaces()[index].GetIpAddresses(), dstIP)
 		}
 	}
 	return next.Server(ctx).Close(ctx, conn)
@@ -18,10 +18,8 @@ package routes
 
 import (
 	"context"
-	"net"
 
 	"github.com/golang/protobuf/ptypes/empty"
-	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
 	"google.golang.org/grpc"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
@@ -30,7 +28,9 @@ import (
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
 )
 
-type setVppRoutesClient struct{}
+type setVppRoutesClient struct {
+	weight uint32
+}
 
 // NewClient creates a NetworkServiceClient chain element to set routes in vpp
 //                                         Client
@@ -52,12 +52,17 @@ type setVppRoutesClient struct{}
 //                              |                           |
 //                              +---------------------------+
 //
-func NewClient() networkservice.NetworkServiceClient {
-	return &setVppRoutesClient{}
+// The SrcRoutes go via the DstIpAddr, and the network of the DstIpAddr gets a route unless the network of the
+// SrcIpAddr covers it.
+func NewClient(opts ...Option) networkservice.NetworkServiceClient {
+	o := applyOptions(opts)
+	return &setVppRoutesClient{
+		weight: o.weight,
+	}
 }
 
 func (s *setVppRoutesClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
-	conn, err := next.Client(ctx).Request(ctx, request)
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +71,7 @@ func (s *setVppRoutesClient) Request(ctx context.Context, request *networkservic
 }
 
 func (s *setVppRoutesClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
-	rv, err := next.Client(ctx).Close(ctx, conn)
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -75,47 +80,6 @@ func (s *setVppRoutesClient) Close(ctx context.Context, conn *networkservice.Con
 }
 
 func (s *setVppRoutesClient) addRoutes(ctx context.Context, conn *networkservice.Connection) {
-	// If we aren't plugging in an interface... nothing to do here
-	if len(vppagent.Config(ctx).GetVppConfig().GetInterfaces()) == 0 {
-		return
-	}
-	// If the interface is nil, nothing to do
-	iface := vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0]
-	if iface == nil {
-		return
-	}
-	// Extract the dstIP and DstNet
-	dstIP, dstNet, err := net.ParseCIDR(conn.GetContext().GetIpContext().GetDstIpAddr())
-	if err != nil {
-		return
-	}
-	_, srcNet, err := net.ParseCIDR(conn.GetContext().GetIpContext().GetSrcIpAddr())
-	if err != nil {
-		return
-	}
-
-	// Loop over any explicit routes returned with the ConnectionContext and add them
-	duplicatedPrefixes := make(map[string]bool)
-	for _, route := range conn.GetContext().GetIpContext().GetSrcRoutes() {
-		if _, ok := duplicatedPrefixes[route.Prefix]; !ok {
-			duplicatedPrefixes[route.Prefix] = true
-			vppagent.Config(ctx).GetVppConfig().Routes = append(vppagent.Config(ctx).GetVppConfig().Routes, &vpp.Route{
-				DstNetwork:        route.Prefix,
-				OutgoingInterface: iface.GetName(),
-			})
-		}
-	}
-	// If srcNet contains dstIP then dstIP is reachable and we are done
-	if _, ok := duplicatedPrefixes[dstNet.String()]; ok || srcNet.Contains(dstIP) {
-		return
-	}
-
-	// Otherwise add a route to dstNet. using dstIP a nextHop
-	if dstIP.IsGlobalUnicast() {
-		vppagent.Config(ctx).GetVppConfig().Routes = append(vppagent.Config(ctx).GetVppConfig().Routes, &vpp.Route{
-			DstNetwork:        dstNet.String(),
-			OutgoingInterface: iface.GetName(),
-			VrfId:             iface.Vrf,
-		})
-	}
+	ipContext := conn.GetContext().GetIpContext()
+	appendRoutes(vppagent.Config(ctx), ipContext.GetSrcIpAddr(), ipContext.GetDstIpAddr(), ipContext.GetSrcRoutes(), s.weight)
 }
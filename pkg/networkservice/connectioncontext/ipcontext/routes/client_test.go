@@ -0,0 +1,89 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routes_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ipcontext/routes"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+func TestSetVppRoutesClient(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "id",
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					SrcIpAddr: "fd00::1/128",
+					DstIpAddr: "fd00::2/128",
+					SrcRoutes: []*networkservice.Route{
+						{Prefix: "fd01::/48"},
+						{Prefix: "fd01:0:1::/64"},
+						{Prefix: "fd01::/48"},
+					},
+				},
+			},
+		},
+	}
+	expected := []*vpp.Route{
+		{DstNetwork: "fd01::/48", NextHopAddr: "fd00::2", OutgoingInterface: InterfaceName, VrfId: Vrf},
+		{DstNetwork: "fd01:0:1::/64", NextHopAddr: "fd00::2", OutgoingInterface: InterfaceName, VrfId: Vrf},
+		{DstNetwork: "fd00::2/128", OutgoingInterface: InterfaceName, VrfId: Vrf},
+	}
+
+	ctx := withInterface(context.Background())
+	conn, err := routes.NewClient().Request(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, expected, vppagent.Config(ctx).GetVppConfig().GetRoutes())
+
+	ctx = withInterface(context.Background())
+	_, err = routes.NewClient().Close(ctx, conn)
+	require.NoError(t, err)
+	assert.Equal(t, expected, vppagent.Config(ctx).GetVppConfig().GetRoutes())
+}
+
+func TestSetVppRoutesClient_NoInterface(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "id",
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					DstIpAddr: "172.16.1.0/32",
+					SrcRoutes: []*networkservice.Route{
+						{Prefix: "10.0.0.0/8"},
+					},
+				},
+			},
+		},
+	}
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := routes.NewClient().Request(ctx, request)
+	require.NoError(t, err)
+	assert.Empty(t, vppagent.Config(ctx).GetVppConfig().GetRoutes())
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routes
+
+import (
+	"net"
+
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+)
+
+// appendRoutes - appends the routes to the peer of the last vpp interface, in the vrf of the interface:
+//                routes - the explicit routes, with the peer IP as next hop, duplicates left out
+//                localAddr, peerAddr - the addresses of either side, the peer network gets a route unless the local
+//                                      network already covers the peer IP
+func appendRoutes(conf *configurator.Config, localAddr, peerAddr string, routes []*networkservice.Route, weight uint32) {
+	index := len(conf.GetVppConfig().GetInterfaces()) - 1
+	if index < 0 {
+		return
+	}
+	iface := conf.GetVppConfig().GetInterfaces()[index]
+	peerIP, peerNet, err := net.ParseCIDR(peerAddr)
+	if err != nil {
+		peerIP, peerNet = nil, nil
+	}
+
+	added := make(map[string]bool)
+	for _, route := range routes {
+		_, prefix, err := net.ParseCIDR(route.GetPrefix())
+		if err != nil || added[prefix.String()] {
+			continue
+		}
+		added[prefix.String()] = true
+		conf.GetVppConfig().Routes = append(conf.GetVppConfig().Routes, &vpp.Route{
+			DstNetwork:        prefix.String(),
+			NextHopAddr:       nextHop(prefix, peerIP),
+			OutgoingInterface: iface.GetName(),
+			VrfId:             iface.GetVrf(),
+			Weight:            weight,
+		})
+	}
+
+	if peerNet == nil || added[peerNet.String()] || !peerIP.IsGlobalUnicast() {
+		return
+	}
+	// If the local network contains the peer IP, the peer is reachable and we are done
+	if _, localNet, err := net.ParseCIDR(localAddr); err == nil && localNet.Contains(peerIP) {
+		return
+	}
+	conf.GetVppConfig().Routes = append(conf.GetVppConfig().Routes, &vpp.Route{
+		DstNetwork:        peerNet.String(),
+		OutgoingInterface: iface.GetName(),
+		VrfId:             iface.GetVrf(),
+		Weight:            weight,
+	})
+}
+
+// nextHop - returns the peer IP as next hop for prefix, or "" for a route straight out of the interface if there is no
+//           peer IP of the same family
+func nextHop(prefix *net.IPNet, peerIP net.IP) string {
+	if peerIP == nil || (prefix.IP.To4() == nil) != (peerIP.To4() == nil) {
+		return ""
+	}
+	return peerIP.String()
+}
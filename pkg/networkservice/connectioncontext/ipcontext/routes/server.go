@@ -18,10 +18,8 @@ package routes
 
 import (
 	"context"
-	"net"
 
 	"github.com/golang/protobuf/ptypes/empty"
-	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
@@ -29,10 +27,12 @@ import (
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
 )
 
-type setVppRoutesServer struct{}
+type setVppRoutesServer struct {
+	weight uint32
+}
 
-// NewServer creates a NetworkServiceServer chain element to set the ip address on a vpp interface
-// It sets the IP Address on the *vpp* side of an interface plugged into the
+// NewServer creates a NetworkServiceServer chain element to set routes in vpp
+// It sets the routes on the *vpp* side of an interface plugged into the
 // Endpoint.
 //                                         Endpoint
 //                              +---------------------------+
@@ -53,8 +53,13 @@ type setVppRoutesServer struct{}
 //                              |                           |
 //                              +---------------------------+
 //
-func NewServer() networkservice.NetworkServiceServer {
-	return &setVppRoutesServer{}
+// The DstRoutes go via the SrcIpAddr, and the network of the SrcIpAddr gets a route unless the network of the
+// DstIpAddr covers it.
+func NewServer(opts ...Option) networkservice.NetworkServiceServer {
+	o := applyOptions(opts)
+	return &setVppRoutesServer{
+		weight: o.weight,
+	}
 }
 
 func (s *setVppRoutesServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
@@ -68,21 +73,6 @@ func (s *setVppRoutesServer) Close(ctx context.Context, conn *networkservice.Con
 }
 
 func (s *setVppRoutesServer) addRoutes(ctx context.Context, conn *networkservice.Connection) {
-	if conn.GetContext().GetIpContext().GetSrcIpAddr() == "" {
-		return
-	}
-	srcIP, srcNet, err := net.ParseCIDR(conn.GetContext().GetIpContext().GetSrcIpAddr())
-	if err != nil {
-		return
-	}
-	conf := vppagent.Config(ctx)
-	index := len(conf.GetVppConfig().GetInterfaces()) - 1
-	if index >= 0 && srcIP.IsGlobalUnicast() {
-		iface := conf.GetVppConfig().GetInterfaces()[index]
-		vppagent.Config(ctx).GetVppConfig().Routes = append(vppagent.Config(ctx).GetVppConfig().Routes, &vpp.Route{
-			DstNetwork:        srcNet.String(),
-			OutgoingInterface: iface.GetName(),
-			VrfId:             iface.Vrf,
-		})
-	}
+	ipContext := conn.GetContext().GetIpContext()
+	appendRoutes(vppagent.Config(ctx), ipContext.GetDstIpAddr(), ipContext.GetSrcIpAddr(), ipContext.GetDstRoutes(), s.weight)
 }
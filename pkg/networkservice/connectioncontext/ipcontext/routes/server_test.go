@@ -0,0 +1,131 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routes_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ipcontext/routes"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const (
+	InterfaceName = "iface"
+	Vrf           = 2
+)
+
+func withInterface(ctx context.Context) context.Context {
+	ctx = vppagent.WithConfig(ctx)
+	vppagent.Config(ctx).GetVppConfig().Interfaces = append(vppagent.Config(ctx).GetVppConfig().Interfaces, &vppinterfaces.Interface{
+		Name: InterfaceName,
+		Vrf:  Vrf,
+	})
+	return ctx
+}
+
+func TestSetVppRoutesServer(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "id",
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					SrcIpAddr: "172.16.1.1/32",
+					DstIpAddr: "172.16.2.1/32",
+					DstRoutes: []*networkservice.Route{
+						{Prefix: "10.0.0.0/8"},
+						// Overlapping prefixes are both routed
+						{Prefix: "10.1.0.0/16"},
+						// Duplicate prefixes are routed once
+						{Prefix: "10.1.2.3/16"},
+						// The peer IP of the wrong family is no next hop
+						{Prefix: "fd00::/64"},
+					},
+				},
+			},
+		},
+	}
+	expected := []*vpp.Route{
+		{DstNetwork: "10.0.0.0/8", NextHopAddr: "172.16.1.1", OutgoingInterface: InterfaceName, VrfId: Vrf},
+		{DstNetwork: "10.1.0.0/16", NextHopAddr: "172.16.1.1", OutgoingInterface: InterfaceName, VrfId: Vrf},
+		{DstNetwork: "fd00::/64", OutgoingInterface: InterfaceName, VrfId: Vrf},
+		{DstNetwork: "172.16.1.1/32", OutgoingInterface: InterfaceName, VrfId: Vrf},
+	}
+
+	ctx := withInterface(context.Background())
+	conn, err := routes.NewServer().Request(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, expected, vppagent.Config(ctx).GetVppConfig().GetRoutes())
+
+	ctx = withInterface(context.Background())
+	_, err = routes.NewServer().Close(ctx, conn)
+	require.NoError(t, err)
+	assert.Equal(t, expected, vppagent.Config(ctx).GetVppConfig().GetRoutes())
+}
+
+func TestSetVppRoutesServer_SameNetwork(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "id",
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					SrcIpAddr: "172.16.1.1/31",
+					DstIpAddr: "172.16.1.0/31",
+				},
+			},
+		},
+	}
+	ctx := withInterface(context.Background())
+	_, err := routes.NewServer().Request(ctx, request)
+	require.NoError(t, err)
+	assert.Empty(t, vppagent.Config(ctx).GetVppConfig().GetRoutes())
+}
+
+func TestSetVppRoutesServer_WithWeight(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "id",
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					SrcIpAddr: "172.16.1.1/31",
+					DstIpAddr: "172.16.1.0/31",
+					DstRoutes: []*networkservice.Route{
+						{Prefix: "10.0.0.0/8"},
+					},
+				},
+			},
+		},
+	}
+	ctx := withInterface(context.Background())
+	_, err := routes.NewServer(routes.WithWeight(3)).Request(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, []*vpp.Route{
+		{DstNetwork: "10.0.0.0/8", NextHopAddr: "172.16.1.1", OutgoingInterface: InterfaceName, VrfId: Vrf, Weight: 3},
+	}, vppagent.Config(ctx).GetVppConfig().GetRoutes())
+}
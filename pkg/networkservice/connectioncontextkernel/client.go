@@ -23,6 +23,7 @@ import (
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ethernetcontext/arps"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/ipaddress"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/routes"
 )
@@ -54,5 +55,6 @@ func NewClient() networkservice.NetworkServiceClient {
 	return chain.NewNetworkServiceClient(
 		routes.NewClient(),
 		ipaddress.NewClient(),
+		arps.NewClient(),
 	)
 }
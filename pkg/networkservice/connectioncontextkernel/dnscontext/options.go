@@ -0,0 +1,74 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnscontext
+
+import (
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
+)
+
+// ResolvConfPathFunc - returns the path of the resolv.conf used in the network namespace of the connection
+type ResolvConfPathFunc func(conn *networkservice.Connection) (string, error)
+
+type option struct {
+	resolvConfPath ResolvConfPathFunc
+}
+
+// Option - option for use with dnscontext.NewServer(...)
+type Option func(opt *option)
+
+// WithResolvConfPath - sets the ResolvConfPathFunc.  By default only the resolv.conf of a named network namespace is
+//                      found from the NetNSURL of the kernel Mechanism - /etc/netns/<name>/resolv.conf, as used by
+//                      'ip netns exec'.  The network namespace of a pod is held by its pause container, whose root is
+//                      not where the containers of the pod get their resolv.conf from, so for those a
+//                      ResolvConfPathFunc returning the resolv.conf the pod's containers mount has to be set.
+func WithResolvConfPath(resolvConfPath ResolvConfPathFunc) Option {
+	return func(opt *option) {
+		opt.resolvConfPath = resolvConfPath
+	}
+}
+
+func applyOptions(opts []Option) *option {
+	o := &option{
+		resolvConfPath: defaultResolvConfPath(netnsurl.NewResolver()),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func defaultResolvConfPath(resolver *netnsurl.Resolver) ResolvConfPathFunc {
+	return func(conn *networkservice.Connection) (string, error) {
+		netNSURL := kernel.ToMechanism(conn.GetMechanism()).GetNetNSURL()
+		netNS, err := resolver.Resolve(netNSURL)
+		if err != nil {
+			return "", err
+		}
+		if netNS.GetType() == linuxnamespace.NetNamespace_NSID {
+			return filepath.Join("/etc/netns", netNS.GetReference(), "resolv.conf"), nil
+		}
+		return "", errors.Errorf("unable to find the resolv.conf of NetNSURL: %q, see dnscontext.WithResolvConfPath", netNSURL)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnscontext
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+const (
+	resolvConfHeader = "# DNS servers and search domains of Network Service Mesh connections"
+	resolvConfMode   = 0644
+	// originalSuffix - suffix of the file the original resolv.conf is kept in while it is changed
+	originalSuffix = ".nsm-original"
+	// maxNameservers - the number of nameservers the resolver uses, MAXNS in resolv.h
+	maxNameservers = 3
+)
+
+// resolvConf - a resolv.conf shared by the connections of a network namespace
+type resolvConf struct {
+	path     string
+	original []byte
+	existed  bool
+	// configs - the DNSConfigs per connection ID
+	configs map[string][]*networkservice.DNSConfig
+}
+
+// newResolvConf - reads the original resolv.conf at path.  The original is kept in a file next to it, so that it can
+//                 still be restored once the resolv.conf left changed by a previous run is read.
+func newResolvConf(path string) (*resolvConf, error) {
+	r := &resolvConf{
+		path:    path,
+		configs: make(map[string][]*networkservice.DNSConfig),
+	}
+	current, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to read %s", path)
+	}
+	if err != nil || !bytes.HasPrefix(current, []byte(resolvConfHeader+"\n")) {
+		// Not changed by us
+		r.original, r.existed = current, err == nil
+		if r.existed {
+			if err = ioutil.WriteFile(path+originalSuffix, current, resolvConfMode); err != nil {
+				return nil, errors.Wrapf(err, "failed to save the original of %s", path)
+			}
+		}
+		return r, nil
+	}
+	// Changed by a previous run, the original is only missing if there was none
+	original, err := ioutil.ReadFile(path + originalSuffix)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to read the original of %s", path)
+	}
+	r.original, r.existed = original, err == nil
+	return r, nil
+}
+
+// write - writes the original resolv.conf merged with the DNSConfigs of all the connections.  The file is written in
+//         place, as resolv.conf is often a bind mount.
+func (r *resolvConf) write(ctx context.Context) error {
+	content, dropped := r.merged()
+	if len(dropped) > 0 {
+		log.Entry(ctx).Warnf("%s can only have %d nameservers, dropped the nameservers of the connections: %v",
+			r.path, maxNameservers, dropped)
+	}
+	if err := ioutil.WriteFile(r.path, content, resolvConfMode); err != nil {
+		return errors.Wrapf(err, "failed to write %s", r.path)
+	}
+	return nil
+}
+
+// restore - puts back the original resolv.conf
+func (r *resolvConf) restore() error {
+	if r.existed {
+		if err := ioutil.WriteFile(r.path, r.original, resolvConfMode); err != nil {
+			return errors.Wrapf(err, "failed to restore %s", r.path)
+		}
+	} else if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to remove %s", r.path)
+	}
+	if err := os.Remove(r.path + originalSuffix); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to remove the original of %s", r.path)
+	}
+	return nil
+}
+
+// merged - returns the original resolv.conf with the nameservers and search domains of the connections added, in
+//          connection ID order so the result doesn't change from one write to the next.  The nameservers of the
+//          connections go first, so that the names they serve are resolved by them, followed by the original ones,
+//          and only as many nameservers are kept as the resolver uses.  The nameservers of the connections that
+//          didn't fit are returned as dropped.  A single search line is written, as only the last one counts.
+func (r *resolvConf) merged() (content []byte, dropped []string) {
+	var nameservers, originalNameservers, domains []string
+	seen := make(map[string]bool)
+	add := func(list *[]string, kind string, values ...string) {
+		for _, value := range values {
+			if value != "" && !seen[kind+value] {
+				seen[kind+value] = true
+				*list = append(*list, value)
+			}
+		}
+	}
+
+	var rest []string
+	for _, line := range strings.Split(strings.TrimSpace(string(r.original)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 1 {
+			switch fields[0] {
+			case "nameserver":
+				originalNameservers = append(originalNameservers, fields[1])
+				continue
+			case "search", "domain":
+				add(&domains, "search", fields[1:]...)
+				continue
+			}
+		}
+		if line != "" {
+			rest = append(rest, line)
+		}
+	}
+
+	connIDs := make([]string, 0, len(r.configs))
+	for connID := range r.configs {
+		connIDs = append(connIDs, connID)
+	}
+	sort.Strings(connIDs)
+	for _, connID := range connIDs {
+		for _, config := range r.configs[connID] {
+			add(&nameservers, "nameserver", config.GetDnsServerIps()...)
+			add(&domains, "search", config.GetSearchDomains()...)
+		}
+	}
+	if len(nameservers) > maxNameservers {
+		dropped = nameservers[maxNameservers:]
+	}
+	add(&nameservers, "nameserver", originalNameservers...)
+	if len(nameservers) > maxNameservers {
+		nameservers = nameservers[:maxNameservers]
+	}
+
+	lines := []string{resolvConfHeader}
+	for _, nameserver := range nameservers {
+		lines = append(lines, "nameserver "+nameserver)
+	}
+	if len(domains) > 0 {
+		lines = append(lines, "search "+strings.Join(domains, " "))
+	}
+	lines = append(lines, rest...)
+	return []byte(strings.Join(lines, "\n") + "\n"), dropped
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dnscontext provides networkservice chain elements that apply the DNSContext to the network namespace of
+// kernel interfaces
+package dnscontext
+
+import (
+	"context"
+	"sync"
+
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+type dnsContextServer struct {
+	resolvConfPath ResolvConfPathFunc
+
+	mu sync.Mutex
+	// files - the resolv.confs in use, per path
+	files map[string]*resolvConf
+	// paths - the resolv.conf path, per connection ID
+	paths map[string]string
+}
+
+// NewServer creates a NetworkServiceServer chain element that puts the DNS servers and search domains of the
+// DNSContext into the resolv.conf of the network namespace of the kernel interface plugged into the Endpoint.
+// The resolv.conf is shared by all the connections into the network namespace, and is restored once the last of them
+// is closed.  As it changes files in the network namespace of the client, it is not part of
+// connectioncontextkernel.NewServer() and has to be added to the chain explicitly.
+func NewServer(opts ...Option) networkservice.NetworkServiceServer {
+	o := applyOptions(opts)
+	return &dnsContextServer{
+		resolvConfPath: o.resolvConfPath,
+		files:          make(map[string]*resolvConf),
+		paths:          make(map[string]string),
+	}
+}
+
+func (d *dnsContextServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if kernel.ToMechanism(conn.GetMechanism()) == nil {
+		return conn, nil
+	}
+	configs := conn.GetContext().GetDnsContext().GetConfigs()
+	if len(configs) == 0 {
+		// The DNSContext may have been dropped on refresh
+		if err = d.remove(ctx, conn.GetId()); err != nil {
+			return nil, err
+		}
+		return conn, nil
+	}
+	path, err := d.resolvConfPath(conn)
+	if err != nil {
+		return nil, err
+	}
+	if err = d.apply(ctx, conn.GetId(), path, configs); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (d *dnsContextServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	rv, err := next.Server(ctx).Close(ctx, conn)
+	if removeErr := d.remove(ctx, conn.GetId()); removeErr != nil {
+		log.Entry(ctx).Warn(removeErr)
+	}
+	return rv, err
+}
+
+func (d *dnsContextServer) apply(ctx context.Context, connID, path string, configs []*networkservice.DNSConfig) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if previous, ok := d.paths[connID]; ok && previous != path {
+		if err := d.removeLocked(ctx, connID); err != nil {
+			return err
+		}
+	}
+	file, ok := d.files[path]
+	if !ok {
+		var err error
+		if file, err = newResolvConf(path); err != nil {
+			return err
+		}
+		d.files[path] = file
+	}
+	file.configs[connID] = configs
+	d.paths[connID] = path
+	if err := file.write(ctx); err != nil {
+		_ = d.removeLocked(ctx, connID)
+		return err
+	}
+	return nil
+}
+
+func (d *dnsContextServer) remove(ctx context.Context, connID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.removeLocked(ctx, connID)
+}
+
+func (d *dnsContextServer) removeLocked(ctx context.Context, connID string) error {
+	path, ok := d.paths[connID]
+	if !ok {
+		return nil
+	}
+	delete(d.paths, connID)
+	file := d.files[path]
+	delete(file.configs, connID)
+	if len(file.configs) > 0 {
+		return file.write(ctx)
+	}
+	delete(d.files, path)
+	return file.restore()
+}
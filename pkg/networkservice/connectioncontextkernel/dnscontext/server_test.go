@@ -0,0 +1,194 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnscontext_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/dnscontext"
+)
+
+const original = `# original
+nameserver 10.96.0.10
+search default.svc.cluster.local cluster.local
+options ndots:5
+`
+
+func testRequest(id string, configs ...*networkservice.DNSConfig) *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: id,
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: kernel.MECHANISM,
+			},
+			Context: &networkservice.ConnectionContext{
+				DnsContext: &networkservice.DNSContext{
+					Configs: configs,
+				},
+			},
+		},
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestDNSContextServer(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	resolvConf := filepath.Join(t.TempDir(), "resolv.conf")
+	require.NoError(t, ioutil.WriteFile(resolvConf, []byte(original), 0600))
+	serverUnderTest := dnscontext.NewServer(dnscontext.WithResolvConfPath(func(*networkservice.Connection) (string, error) {
+		return resolvConf, nil
+	}))
+
+	first := testRequest("1", &networkservice.DNSConfig{
+		DnsServerIps:  []string{"172.16.0.2"},
+		SearchDomains: []string{"my.service"},
+	})
+	_, err := serverUnderTest.Request(context.Background(), first)
+	require.NoError(t, err)
+	second := testRequest("2", &networkservice.DNSConfig{
+		DnsServerIps:  []string{"172.16.1.2", "172.16.0.2"},
+		SearchDomains: []string{"other.service"},
+	})
+	_, err = serverUnderTest.Request(context.Background(), second)
+	require.NoError(t, err)
+	assert.Equal(t, `# DNS servers and search domains of Network Service Mesh connections
+nameserver 172.16.0.2
+nameserver 172.16.1.2
+nameserver 10.96.0.10
+search default.svc.cluster.local cluster.local my.service other.service
+# original
+options ndots:5
+`, readFile(t, resolvConf))
+
+	_, err = serverUnderTest.Close(context.Background(), first.GetConnection())
+	require.NoError(t, err)
+	assert.Equal(t, `# DNS servers and search domains of Network Service Mesh connections
+nameserver 172.16.1.2
+nameserver 172.16.0.2
+nameserver 10.96.0.10
+search default.svc.cluster.local cluster.local other.service
+# original
+options ndots:5
+`, readFile(t, resolvConf))
+
+	_, err = serverUnderTest.Close(context.Background(), second.GetConnection())
+	require.NoError(t, err)
+	assert.Equal(t, original, readFile(t, resolvConf))
+	_, err = os.Stat(resolvConf + ".nsm-original")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDNSContextServer_MaxNameservers(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	resolvConf := filepath.Join(t.TempDir(), "resolv.conf")
+	require.NoError(t, ioutil.WriteFile(resolvConf, []byte("nameserver 10.96.0.10\nnameserver 10.96.0.11\n"), 0600))
+	serverUnderTest := dnscontext.NewServer(dnscontext.WithResolvConfPath(func(*networkservice.Connection) (string, error) {
+		return resolvConf, nil
+	}))
+
+	request := testRequest("1", &networkservice.DNSConfig{DnsServerIps: []string{"172.16.0.2", "172.16.0.3"}})
+	_, err := serverUnderTest.Request(context.Background(), request)
+	require.NoError(t, err)
+	// The nameservers of the connection go first, the original ones are kept within the 3 the resolver uses
+	assert.Equal(t, `# DNS servers and search domains of Network Service Mesh connections
+nameserver 172.16.0.2
+nameserver 172.16.0.3
+nameserver 10.96.0.10
+`, readFile(t, resolvConf))
+}
+
+func TestDNSContextServer_FullResolvConf(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	resolvConf := filepath.Join(t.TempDir(), "resolv.conf")
+	full := "nameserver 10.96.0.10\nnameserver 10.96.0.11\nnameserver 10.96.0.12\n"
+	require.NoError(t, ioutil.WriteFile(resolvConf, []byte(full), 0600))
+	serverUnderTest := dnscontext.NewServer(dnscontext.WithResolvConfPath(func(*networkservice.Connection) (string, error) {
+		return resolvConf, nil
+	}))
+
+	request := testRequest("1", &networkservice.DNSConfig{DnsServerIps: []string{"172.16.0.2"}})
+	_, err := serverUnderTest.Request(context.Background(), request)
+	require.NoError(t, err)
+	// The nameserver of the connection still makes it in, the last original one is dropped
+	assert.Equal(t, `# DNS servers and search domains of Network Service Mesh connections
+nameserver 172.16.0.2
+nameserver 10.96.0.10
+nameserver 10.96.0.11
+`, readFile(t, resolvConf))
+
+	_, err = serverUnderTest.Close(context.Background(), request.GetConnection())
+	require.NoError(t, err)
+	assert.Equal(t, full, readFile(t, resolvConf))
+}
+
+func TestDNSContextServer_Restart(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	resolvConf := filepath.Join(t.TempDir(), "resolv.conf")
+	require.NoError(t, ioutil.WriteFile(resolvConf, []byte(original), 0600))
+	resolvConfPath := dnscontext.WithResolvConfPath(func(*networkservice.Connection) (string, error) {
+		return resolvConf, nil
+	})
+
+	request := testRequest("1", &networkservice.DNSConfig{DnsServerIps: []string{"172.16.0.2"}})
+	_, err := dnscontext.NewServer(resolvConfPath).Request(context.Background(), request)
+	require.NoError(t, err)
+
+	// A new server finds the resolv.conf already changed, and still restores the original
+	serverUnderTest := dnscontext.NewServer(resolvConfPath)
+	_, err = serverUnderTest.Request(context.Background(), request)
+	require.NoError(t, err)
+	assert.Contains(t, readFile(t, resolvConf), "nameserver 172.16.0.2\n")
+	_, err = serverUnderTest.Close(context.Background(), request.GetConnection())
+	require.NoError(t, err)
+	assert.Equal(t, original, readFile(t, resolvConf))
+}
+
+func TestDNSContextServer_NoResolvConf(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	resolvConf := filepath.Join(t.TempDir(), "resolv.conf")
+	serverUnderTest := dnscontext.NewServer(dnscontext.WithResolvConfPath(func(*networkservice.Connection) (string, error) {
+		return resolvConf, nil
+	}))
+	request := testRequest("1", &networkservice.DNSConfig{DnsServerIps: []string{"172.16.0.2"}})
+	_, err := serverUnderTest.Request(context.Background(), request)
+	require.NoError(t, err)
+	assert.Contains(t, readFile(t, resolvConf), "nameserver 172.16.0.2\n")
+
+	// Refreshed without a DNSContext
+	_, err = serverUnderTest.Request(context.Background(), testRequest("1"))
+	require.NoError(t, err)
+	_, err = os.Stat(resolvConf)
+	assert.True(t, os.IsNotExist(err))
+}
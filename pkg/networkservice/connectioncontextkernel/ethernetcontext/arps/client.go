@@ -0,0 +1,68 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arps
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+type setKernelArpsClient struct{}
+
+// NewClient provides a NetworkServiceClient that sets the arp entries for kernel linux config, the one of the SrcIpAddr
+// along with the ones of the IpNeighbors.  The arp entries are set on the kernel interface leaving the Client.
+func NewClient() networkservice.NetworkServiceClient {
+	return &setKernelArpsClient{}
+}
+
+func (c *setKernelArpsClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.appendArpEntries(ctx, conn)
+	return conn, nil
+}
+
+func (c *setKernelArpsClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.appendArpEntries(ctx, conn)
+	return rv, nil
+}
+
+func (c *setKernelArpsClient) appendArpEntries(ctx context.Context, conn *networkservice.Connection) {
+	config := vppagent.Config(ctx)
+	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil && len(config.GetLinuxConfig().GetInterfaces()) > 0 {
+		iface := config.GetLinuxConfig().GetInterfaces()[len(config.GetLinuxConfig().GetInterfaces())-1]
+		appendArpEntry(config, iface.GetName(), conn.GetContext().GetIpContext().GetSrcIpAddr(), conn.GetContext().GetEthernetContext().GetSrcMac())
+		for _, neighbor := range conn.GetContext().GetIpContext().GetIpNeighbors() {
+			appendArpEntry(config, iface.GetName(), neighbor.GetIp(), neighbor.GetHardwareAddress())
+		}
+	}
+}
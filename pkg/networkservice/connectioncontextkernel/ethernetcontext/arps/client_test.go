@@ -0,0 +1,92 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/stretchr/testify/assert"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+func TestClient(t *testing.T) {
+	conn := &networkservice.Connection{
+		Id: "1",
+		Mechanism: &networkservice.Mechanism{
+			Type: kernel.MECHANISM,
+		},
+		Context: &networkservice.ConnectionContext{
+			EthernetContext: &networkservice.EthernetContext{
+				SrcMac: "0a:1b:3c:4d:5e:6f",
+			},
+			IpContext: &networkservice.IPContext{
+				SrcIpAddr: "172.16.1.1/31",
+			},
+		},
+	}
+	expected := []*linux.ARPEntry{
+		{Interface: "client-1", IpAddress: "172.16.1.1", HwAddress: "0a:1b:3c:4d:5e:6f"},
+	}
+	for _, closing := range []bool{false, true} {
+		ctx := vppagent.WithConfig(context.Background())
+		vppagent.Config(ctx).GetLinuxConfig().Interfaces = []*linux.Interface{{Name: "client-1"}}
+		var err error
+		if closing {
+			_, err = NewClient().Close(ctx, conn)
+		} else {
+			_, err = NewClient().Request(ctx, &networkservice.NetworkServiceRequest{Connection: conn})
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, expected, vppagent.Config(ctx).GetLinuxConfig().GetArpEntries())
+	}
+}
+
+func TestClientNeighbors(t *testing.T) {
+	conn := &networkservice.Connection{
+		Id: "1",
+		Mechanism: &networkservice.Mechanism{
+			Type: kernel.MECHANISM,
+		},
+		Context: &networkservice.ConnectionContext{
+			EthernetContext: &networkservice.EthernetContext{
+				SrcMac: "0a:1b:3c:4d:5e:6f",
+			},
+			IpContext: &networkservice.IPContext{
+				SrcIpAddr: "fd00::1/64",
+				IpNeighbors: []*networkservice.IpNeighbor{
+					{Ip: "fd00::3", HardwareAddress: "0a:1b:3c:4d:5e:70"},
+					{Ip: "172.16.1.3/32", HardwareAddress: "0a:1b:3c:4d:5e:71"},
+					{Ip: "invalid", HardwareAddress: "0a:1b:3c:4d:5e:72"},
+				},
+			},
+		},
+	}
+	ctx := vppagent.WithConfig(context.Background())
+	vppagent.Config(ctx).GetLinuxConfig().Interfaces = []*linux.Interface{{Name: "client-1"}}
+	_, err := NewClient().Request(ctx, &networkservice.NetworkServiceRequest{Connection: conn})
+	assert.Nil(t, err)
+	assert.Equal(t, []*linux.ARPEntry{
+		{Interface: "client-1", IpAddress: "fd00::1", HwAddress: "0a:1b:3c:4d:5e:6f"},
+		{Interface: "client-1", IpAddress: "fd00::3", HwAddress: "0a:1b:3c:4d:5e:70"},
+		{Interface: "client-1", IpAddress: "172.16.1.3", HwAddress: "0a:1b:3c:4d:5e:71"},
+	}, vppagent.Config(ctx).GetLinuxConfig().GetArpEntries())
+}
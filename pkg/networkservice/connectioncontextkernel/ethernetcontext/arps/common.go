@@ -0,0 +1,45 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arps
+
+import (
+	"net"
+
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+)
+
+// appendArpEntry - appends the arp entry (the neighbor entry for IPv6) of ipAddr to the linux config, unless either
+//                  address is missing or ipAddr is not an IP address, with or without its prefix length
+func appendArpEntry(conf *configurator.Config, ifaceName, ipAddr, hwAddr string) {
+	ip := parseIP(ipAddr)
+	if ip == nil || hwAddr == "" {
+		return
+	}
+	conf.GetLinuxConfig().ArpEntries = append(conf.GetLinuxConfig().GetArpEntries(), &linux.ARPEntry{
+		Interface: ifaceName,
+		IpAddress: ip.String(),
+		HwAddress: hwAddr,
+	})
+}
+
+func parseIP(ipAddr string) net.IP {
+	if ip, _, err := net.ParseCIDR(ipAddr); err == nil {
+		return ip
+	}
+	return net.ParseIP(ipAddr)
+}
@@ -19,12 +19,10 @@ package arps
 
 import (
 	"context"
-	"strings"
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
-	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
@@ -32,37 +30,30 @@ import (
 
 type setKernelArpsServer struct{}
 
-// NewServer provides a NetworkServiceServer that sets the arp entry for kernel linux config
+// NewServer provides a NetworkServiceServer that sets the arp entries for kernel linux config, the one of the DstIpAddr
+// along with the ones of the IpNeighbors.  The arp entries are set on the kernel interface plugged into the Endpoint.
 func NewServer() networkservice.NetworkServiceServer {
 	return &setKernelArpsServer{}
 }
 
 func (s *setKernelArpsServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
-	config := vppagent.Config(ctx)
-	iface := kernelctx.ServerInterface(ctx)
-	if iface != nil && request.GetConnection().GetContext().GetEthernetContext().GetDstMac() != "" && request.GetConnection().GetContext().GetIpContext().GetDstIpAddr() != "" {
-		config.GetLinuxConfig().ArpEntries = append(config.GetLinuxConfig().GetArpEntries(),
-			&linux.ARPEntry{
-				IpAddress: strings.Split(request.GetConnection().GetContext().GetIpContext().GetDstIpAddr(), "/")[0],
-				Interface: iface.Name,
-				HwAddress: request.Connection.GetContext().GetEthernetContext().GetDstMac(),
-			},
-		)
-	}
+	s.appendArpEntries(ctx, request.GetConnection())
 	return next.Server(ctx).Request(ctx, request)
 }
 
 func (s *setKernelArpsServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
-	config := vppagent.Config(ctx)
+	s.appendArpEntries(ctx, conn)
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+func (s *setKernelArpsServer) appendArpEntries(ctx context.Context, conn *networkservice.Connection) {
 	iface := kernelctx.ServerInterface(ctx)
-	if iface != nil && conn.GetContext().GetEthernetContext().GetDstMac() != "" && conn.GetContext().GetIpContext().GetDstIpAddr() != "" {
-		config.GetLinuxConfig().ArpEntries = append(config.GetLinuxConfig().GetArpEntries(),
-			&linux.ARPEntry{
-				IpAddress: strings.Split(conn.GetContext().GetIpContext().GetDstIpAddr(), "/")[0],
-				Interface: iface.Name,
-				HwAddress: conn.GetContext().GetEthernetContext().GetDstMac(),
-			},
-		)
+	if iface == nil {
+		return
+	}
+	config := vppagent.Config(ctx)
+	appendArpEntry(config, iface.GetName(), conn.GetContext().GetIpContext().GetDstIpAddr(), conn.GetContext().GetEthernetContext().GetDstMac())
+	for _, neighbor := range conn.GetContext().GetIpContext().GetIpNeighbors() {
+		appendArpEntry(config, iface.GetName(), neighbor.GetIp(), neighbor.GetHardwareAddress())
 	}
-	return next.Server(ctx).Close(ctx, conn)
 }
@@ -103,3 +103,37 @@ func (t *testingServer) Close(ctx context.Context, conn *networkservice.Connecti
 	assert.Equal(t, expectedArp, config.LinuxConfig.ArpEntries[0])
 	return result, err
 }
+
+func TestServerNeighbors(t *testing.T) {
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "1",
+			Mechanism: &networkservice.Mechanism{
+				Type: kernel.MECHANISM,
+			},
+			Context: &networkservice.ConnectionContext{
+				EthernetContext: &networkservice.EthernetContext{
+					DstMac: "0a:1b:3c:4d:5e:6f",
+				},
+				IpContext: &networkservice.IPContext{
+					DstIpAddr: "fd00::2/64",
+					IpNeighbors: []*networkservice.IpNeighbor{
+						{Ip: "fd00::3", HardwareAddress: "0a:1b:3c:4d:5e:70"},
+						{Ip: "172.16.1.3/32", HardwareAddress: "0a:1b:3c:4d:5e:71"},
+						{Ip: "invalid", HardwareAddress: "0a:1b:3c:4d:5e:72"},
+					},
+				},
+			},
+		},
+	}
+	ctx := vppagent.WithConfig(context.Background())
+	iface := &linux.Interface{Name: "server-1"}
+	ctx = kernelctx.WithServerInterface(ctx, iface)
+	_, err := NewServer().Request(ctx, request)
+	assert.Nil(t, err)
+	assert.Equal(t, []*linux.ARPEntry{
+		{Interface: "server-1", IpAddress: "fd00::2", HwAddress: "0a:1b:3c:4d:5e:6f"},
+		{Interface: "server-1", IpAddress: "fd00::3", HwAddress: "0a:1b:3c:4d:5e:70"},
+		{Interface: "server-1", IpAddress: "172.16.1.3", HwAddress: "0a:1b:3c:4d:5e:71"},
+	}, vppagent.Config(ctx).GetLinuxConfig().GetArpEntries())
+}
@@ -21,18 +21,26 @@ import (
 	"context"
 
 	"github.com/golang/protobuf/ptypes/empty"
-	"github.com/networkservicemesh/api/pkg/api/networkservice"
-	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
-	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
-	"github.com/sirupsen/logrus"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
 	"google.golang.org/grpc"
 
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/macaddr"
 )
 
-// NewServer creates a NetworkServiceServer chain element to set the EthernetContext for Kernel connection request
+// NewServer creates a NetworkServiceServer chain element to set the EthernetContext for Kernel connection request:
+//             DstMac - mac address of the kernel interface
+//             SrcMac - mac address of the vpp side of the kernel interface
+// The mac addresses set on the interfaces up front, by stablemac for instance, are taken as they are.  Only for the
+// interfaces left without one is the vppagent asked for the actual mac address, and as the configurator can only Dump
+// its whole state that is best avoided by assigning the mac addresses with stablemac.
 func NewServer(сс grpc.ClientConnInterface) networkservice.NetworkServiceServer {
 	return &getMacKernelServer{
 		client: configurator.NewConfiguratorServiceClient(сс),
@@ -44,30 +52,72 @@ type getMacKernelServer struct {
 }
 
 func (s *getMacKernelServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
-	var dstInterface *linux.Interface
+	if mechanism := kernel.ToMechanism(request.GetConnection().GetMechanism()); mechanism == nil {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	var linuxIface *linux.Interface
+	var vppIface *vpp.Interface
 	config := vppagent.Config(ctx)
-	if mechanism := kernel.ToMechanism(request.GetConnection().GetMechanism()); mechanism != nil && len(config.GetLinuxConfig().GetInterfaces()) > 0 {
-		dstInterface = config.LinuxConfig.Interfaces[len(config.LinuxConfig.Interfaces)-1]
+	if linuxIfaces := config.GetLinuxConfig().GetInterfaces(); len(linuxIfaces) > 0 {
+		linuxIface = linuxIfaces[len(linuxIfaces)-1]
 	}
+	if vppIfaces := config.GetVppConfig().GetInterfaces(); len(vppIfaces) > 0 {
+		vppIface = vppIfaces[len(vppIfaces)-1]
+	}
+
 	conn, err := next.Server(ctx).Request(ctx, request)
-	if err == nil && dstInterface != nil {
-		dump, dumpErr := s.client.Dump(context.Background(), &configurator.DumpRequest{})
-		if dumpErr != nil {
-			logrus.Errorf("An error during ConfiguratorClient.Dump, err: %v", dumpErr.Error())
-			return conn, err
-		}
-		for _, iface := range dump.Dump.LinuxConfig.Interfaces {
-			if iface.Name == dstInterface.Name {
-				request.GetConnection().GetContext().EthernetContext = &networkservice.EthernetContext{
-					DstMac: iface.PhysAddress,
-				}
-				break
-			}
-		}
+	if err != nil || (linuxIface == nil && vppIface == nil) {
+		return conn, err
 	}
-	return conn, err
+
+	s.readBack(ctx, conn, linuxIface, vppIface)
+	return conn, nil
 }
 
 func (s *getMacKernelServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
 	return next.Server(ctx).Close(ctx, conn)
 }
+
+// readBack - sets the mac addresses of linuxIface and vppIface in the EthernetContext of conn, asking the vppagent for
+//            the ones missing from the config
+func (s *getMacKernelServer) readBack(ctx context.Context, conn *networkservice.Connection, linuxIface *linux.Interface, vppIface *vpp.Interface) {
+	dstMac, srcMac := linuxIface.GetPhysAddress(), vppIface.GetPhysAddress()
+	if (linuxIface != nil && dstMac == "") || (vppIface != nil && srcMac == "") {
+		// The configurator can only Dump the whole state, so we pick out the interfaces in question
+		dump, err := s.client.Dump(ctx, &configurator.DumpRequest{})
+		if err != nil {
+			log.Entry(ctx).Errorf("error getting the mac addresses from the vppagent: %+v", err)
+		}
+		if dstMac == "" {
+			dstMac = linuxMac(dump.GetDump(), linuxIface.GetName())
+		}
+		if srcMac == "" {
+			srcMac = vppMac(dump.GetDump(), vppIface.GetName())
+		}
+	}
+	ethernetContext := macaddr.EthernetContext(conn)
+	if dstMac != "" {
+		ethernetContext.DstMac = dstMac
+	}
+	if srcMac != "" {
+		ethernetContext.SrcMac = srcMac
+	}
+}
+
+func linuxMac(dump *configurator.Config, name string) string {
+	for _, iface := range dump.GetLinuxConfig().GetInterfaces() {
+		if name != "" && iface.GetName() == name {
+			return iface.GetPhysAddress()
+		}
+	}
+	return ""
+}
+
+func vppMac(dump *configurator.Config, name string) string {
+	for _, iface := range dump.GetVppConfig().GetInterfaces() {
+		if name != "" && iface.GetName() == name {
+			return iface.GetPhysAddress()
+		}
+	}
+	return ""
+}
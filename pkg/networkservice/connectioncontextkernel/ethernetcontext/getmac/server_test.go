@@ -20,6 +20,10 @@ import (
 	"context"
 	"testing"
 
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
@@ -60,6 +64,128 @@ func TestServerBasic(t *testing.T) {
 	assert.NotNil(t, cc)
 }
 
+func TestServerDumpError(t *testing.T) {
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "1",
+			Mechanism: &networkservice.Mechanism{
+				Type: kernel.MECHANISM,
+			},
+		},
+	}
+	linuxIface := &linux.Interface{Name: "DST-1"}
+	vppIface := &vpp.Interface{Name: "DST-1-vpp"}
+
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		&configServer{linuxIface: linuxIface, vppIface: vppIface},
+		&getMacKernelServer{
+			client: &testErrorConfiguratorClient{},
+		})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	conn, err := server.Request(ctx, request)
+	require.NoError(t, err)
+
+	// Without the vppagent the mac addresses stay unknown
+	assert.Empty(t, linuxIface.GetPhysAddress())
+	assert.Empty(t, vppIface.GetPhysAddress())
+	assert.Empty(t, conn.GetContext().GetEthernetContext().GetDstMac())
+	assert.Empty(t, conn.GetContext().GetEthernetContext().GetSrcMac())
+}
+
+func TestServerVppInterface(t *testing.T) {
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "1",
+			Mechanism: &networkservice.Mechanism{
+				Type: kernel.MECHANISM,
+			},
+		},
+	}
+	linuxIface := &linux.Interface{Name: "DST-1"}
+	vppIface := &vpp.Interface{Name: "DST-1-vpp"}
+
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		&configServer{linuxIface: linuxIface, vppIface: vppIface},
+		&getMacKernelServer{
+			client: &testDumpConfiguratorClient{},
+		})
+	conn, err := server.Request(context.Background(), request)
+	require.NoError(t, err)
+
+	// The mac addresses are read back, never set on the interfaces
+	assert.Empty(t, linuxIface.GetPhysAddress())
+	assert.Equal(t, "0a-1b-3c-4d-5e-6f", conn.GetContext().GetEthernetContext().GetDstMac())
+	assert.Equal(t, "4a-1b-3c-4d-5e-6f", conn.GetContext().GetEthernetContext().GetSrcMac())
+}
+
+func TestServerConfiguredMacs(t *testing.T) {
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "1",
+			Mechanism: &networkservice.Mechanism{
+				Type: kernel.MECHANISM,
+			},
+		},
+	}
+	linuxIface := &linux.Interface{Name: "DST-1", PhysAddress: "02:00:00:00:00:01"}
+	vppIface := &vpp.Interface{Name: "DST-1-vpp", PhysAddress: "02:00:00:00:00:02"}
+
+	client := &testCountingConfiguratorClient{}
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		&configServer{linuxIface: linuxIface, vppIface: vppIface},
+		&getMacKernelServer{
+			client: client,
+		})
+	conn, err := server.Request(context.Background(), request)
+	require.NoError(t, err)
+
+	// The mac addresses set on the interfaces are taken without asking the vppagent
+	assert.Equal(t, 0, client.dumps)
+	assert.Equal(t, "02:00:00:00:00:01", conn.GetContext().GetEthernetContext().GetDstMac())
+	assert.Equal(t, "02:00:00:00:00:02", conn.GetContext().GetEthernetContext().GetSrcMac())
+}
+
+type configServer struct {
+	linuxIface *linux.Interface
+	vppIface   *vpp.Interface
+}
+
+func (c *configServer) Request(ctx context.Context, in *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	config := vppagent.Config(ctx)
+	config.GetLinuxConfig().Interfaces = append(config.GetLinuxConfig().Interfaces, c.linuxIface)
+	config.GetVppConfig().Interfaces = append(config.GetVppConfig().Interfaces, c.vppIface)
+	return next.Server(ctx).Request(ctx, in)
+}
+
+func (c *configServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+type testErrorConfiguratorClient struct {
+	testDumpConfiguratorClient
+}
+
+func (t *testErrorConfiguratorClient) Dump(ctx context.Context, in *configurator.DumpRequest, opts ...grpc.CallOption) (*configurator.DumpResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("dump failed")
+}
+
+type testCountingConfiguratorClient struct {
+	testDumpConfiguratorClient
+	dumps int
+}
+
+func (t *testCountingConfiguratorClient) Dump(ctx context.Context, in *configurator.DumpRequest, opts ...grpc.CallOption) (*configurator.DumpResponse, error) {
+	t.dumps++
+	return t.testDumpConfiguratorClient.Dump(ctx, in, opts...)
+}
+
 type testDumpConfiguratorClient struct {
 }
 
@@ -98,6 +224,14 @@ func (t *testDumpConfiguratorClient) Dump(ctx context.Context, in *configurator.
 					},
 				},
 			},
+			VppConfig: &vpp.ConfigData{
+				Interfaces: []*vpp.Interface{
+					{
+						Name:        "DST-1-vpp",
+						PhysAddress: "4a-1b-3c-4d-5e-6f",
+					},
+				},
+			},
 		},
 	}, nil
 }
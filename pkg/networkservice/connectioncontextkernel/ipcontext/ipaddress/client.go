@@ -28,6 +28,7 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
 )
 
 type setIPKernelClient struct{}
@@ -69,7 +70,7 @@ func (s *setIPKernelClient) Request(ctx context.Context, request *networkservice
 		index := len(conf.GetLinuxConfig().GetInterfaces()) - 1
 		dstIP := conn.GetContext().GetIpContext().GetDstIpAddr()
 		if dstIP != "" {
-			conf.GetLinuxConfig().GetInterfaces()[index].IpAddresses = []string{dstIP}
+			conf.GetLinuxConfig().GetInterfaces()[index].IpAddresses = ipaddrs.Append(ctx, conf.GetLinuxConfig().GetInterfaces()[index].GetIpAddresses(), dstIP)
 		}
 	}
 	return conn, nil
@@ -85,7 +86,7 @@ func (s *setIPKernelClient) Close(ctx context.Context, conn *networkservice.Conn
 		index := len(conf.GetLinuxConfig().GetInterfaces()) - 1
 		dstIP := conn.GetContext().GetIpContext().GetDstIpAddr()
 		if dstIP != "" {
-			conf.GetLinuxConfig().GetInterfaces()[index].IpAddresses = []string{conn.GetContext().GetIpContext().GetDstIpAddr()}
+			conf.GetLinuxConfig().GetInterfaces()[index].IpAddresses = ipaddrs.Append(ctx, conf.GetLinuxConfig().GetInterfaces()[index].GetIpAddresses(), dstIP)
 		}
 	}
 	return e, err
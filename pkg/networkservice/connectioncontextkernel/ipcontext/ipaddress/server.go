@@ -24,6 +24,7 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
 )
 
@@ -61,7 +62,7 @@ func (s *setIPKernelServer) Request(ctx context.Context, request *networkservice
 	if iface != nil {
 		srcIP := request.GetConnection().GetContext().GetIpContext().GetSrcIpAddr()
 		if srcIP != "" {
-			iface.IpAddresses = append(iface.GetIpAddresses(), srcIP)
+			iface.IpAddresses = ipaddrs.Append(ctx, iface.GetIpAddresses(), srcIP)
 		}
 	}
 	return next.Server(ctx).Request(ctx, request)
@@ -72,7 +73,7 @@ func (s *setIPKernelServer) Close(ctx context.Context, conn *networkservice.Conn
 	if iface != nil {
 		srcIP := conn.GetContext().GetIpContext().GetSrcIpAddr()
 		if srcIP != "" {
-			iface.IpAddresses = append(iface.GetIpAddresses(), srcIP)
+			iface.IpAddresses = ipaddrs.Append(ctx, iface.GetIpAddresses(), srcIP)
 		}
 	}
 	return next.Server(ctx).Close(ctx, conn)
@@ -31,7 +31,9 @@ import (
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
 )
 
-type setKernelRouteClient struct{}
+type setKernelRouteClient struct {
+	metric uint32
+}
 
 // NewClient creates a NetworkServiceClient that will put the routes from the connection context into
 //  the kernel network namespace kernel interface being inserted iff the
@@ -55,8 +57,13 @@ type setKernelRouteClient struct{}
 //  |                               |         |                           |
 //  +- - - - - - - - - - - - - - - -+         +---------------------------+
 //
-func NewClient() networkservice.NetworkServiceClient {
-	return &setKernelRouteClient{}
+// The route is to the prefix of the SrcIpAddr, which the Client has to stay reachable at, so the ExcludedPrefixes are
+// not taken out of it.
+func NewClient(opts ...Option) networkservice.NetworkServiceClient {
+	o := applyOptions(opts)
+	return &setKernelRouteClient{
+		metric: o.metric,
+	}
 }
 
 func (s *setKernelRouteClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
@@ -94,6 +101,7 @@ func (s *setKernelRouteClient) addRoutes(ctx context.Context, conn *networkservi
 				DstNetwork:        srcNet.String(),
 				OutgoingInterface: iface.GetName(),
 				Scope:             linuxl3.Route_LINK,
+				Metric:            s.metric,
 			})
 		}
 	}
@@ -0,0 +1,81 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routes
+
+import (
+	"net"
+)
+
+// excludedNetworks - returns the networks of the excluded prefixes, skipping any that don't parse
+func excludedNetworks(prefixes []string) []*net.IPNet {
+	var rv []*net.IPNet
+	for _, prefix := range prefixes {
+		if _, network, err := net.ParseCIDR(prefix); err == nil {
+			rv = append(rv, network)
+		}
+	}
+	return rv
+}
+
+// subtract - returns what is left of network once the excluded networks are taken out of it, as the fewest networks
+//            covering it
+func subtract(network *net.IPNet, excluded []*net.IPNet) []*net.IPNet {
+	rv := []*net.IPNet{network}
+	for _, exclude := range excluded {
+		var left []*net.IPNet
+		for _, n := range rv {
+			left = append(left, subtractOne(n, exclude)...)
+		}
+		rv = left
+	}
+	return rv
+}
+
+func subtractOne(network, exclude *net.IPNet) []*net.IPNet {
+	ones, bits := network.Mask.Size()
+	excludeOnes, excludeBits := exclude.Mask.Size()
+	if bits != excludeBits {
+		return []*net.IPNet{network}
+	}
+	if excludeOnes <= ones {
+		if exclude.Contains(network.IP) {
+			return nil
+		}
+		return []*net.IPNet{network}
+	}
+	if !network.Contains(exclude.IP) {
+		return []*net.IPNet{network}
+	}
+	// Halve the network until we get to the excluded one, keeping the halves it is not in
+	var rv []*net.IPNet
+	current := network
+	for ones++; ones <= excludeOnes; ones++ {
+		mask := net.CIDRMask(ones, bits)
+		lower := &net.IPNet{IP: current.IP.Mask(mask), Mask: mask}
+		upperIP := lower.IP.Mask(mask)
+		upperIP[(ones-1)/8] |= 0x80 >> uint((ones-1)%8)
+		upper := &net.IPNet{IP: upperIP, Mask: mask}
+		if lower.Contains(exclude.IP) {
+			rv = append(rv, upper)
+			current = lower
+		} else {
+			rv = append(rv, lower)
+			current = upper
+		}
+	}
+	return rv
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routes
+
+type option struct {
+	metric uint32
+}
+
+// Option - option for use with routes.NewServer(...) and routes.NewClient(...)
+type Option func(opt *option)
+
+// WithMetric - sets the metric of the routes, so the routes of one network service can be preferred over those of
+//              another or of the pod network
+func WithMetric(metric uint32) Option {
+	return func(opt *option) {
+		opt.metric = metric
+	}
+}
+
+func applyOptions(opts []Option) *option {
+	o := &option{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
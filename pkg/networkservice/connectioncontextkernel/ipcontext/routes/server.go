@@ -23,6 +23,8 @@ import (
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/pkg/errors"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
 	linuxl3 "go.ligato.io/vpp-agent/v3/proto/ligato/linux/l3"
 
@@ -33,7 +35,9 @@ import (
 	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
 )
 
-type setKernelRoute struct{}
+type setKernelRoute struct {
+	metric uint32
+}
 
 // NewServer creates a NetworkServiceServer that will put the routes from the connection context into
 //  connection context into the kernel network namespace kernel interface being inserted iff the
@@ -57,53 +61,86 @@ type setKernelRoute struct{}
 //  |                               |         |                           |
 //  +- - - - - - - - - - - - - - - -+         +---------------------------+
 //
-func NewServer() networkservice.NetworkServiceServer {
-	return &setKernelRoute{}
+// The ExcludedPrefixes are taken out of the SrcRoutes, so traffic to them never goes through the kernel interface.  The
+// prefix of the DstIpAddr, the gateway of the SrcRoutes, is never excluded.  A SrcRoute with a malformed prefix fails the
+// Request.
+// Note: policy routing is not supported.  vpp-agent v3.1.0 has no model for linux routing tables or ip rules, so the
+// routes go to the main table rather than to a table per connection selected by the source address, and overlapping
+// routes of several connections into the same network namespace are not told apart.
+func NewServer(opts ...Option) networkservice.NetworkServiceServer {
+	o := applyOptions(opts)
+	return &setKernelRoute{
+		metric: o.metric,
+	}
 }
 
 func (s *setKernelRoute) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
-	s.addRoutes(ctx, request.GetConnection())
+	if err := s.addRoutes(ctx, request.GetConnection()); err != nil {
+		return nil, err
+	}
 	return next.Server(ctx).Request(ctx, request)
 }
 
 func (s *setKernelRoute) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
-	s.addRoutes(ctx, conn)
+	if err := s.addRoutes(ctx, conn); err != nil {
+		// The routes of the well-formed prefixes are still removed
+		log.Entry(ctx).Warn(err)
+	}
 	return next.Server(ctx).Close(ctx, conn)
 }
 
-func (s *setKernelRoute) addRoutes(ctx context.Context, conn *networkservice.Connection) {
+// addRoutes - adds the routes of the connection to the linux config, returning the error of the first malformed
+//             SrcRoute prefix once the routes of the other ones are added
+func (s *setKernelRoute) addRoutes(ctx context.Context, conn *networkservice.Connection) (prefixErr error) {
 	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
+		excluded := excludedNetworks(conn.GetContext().GetIpContext().GetExcludedPrefixes())
 		duplicatedPrefixes := make(map[string]bool)
+		added := make(map[string]bool)
 		for _, route := range conn.GetContext().GetIpContext().GetSrcRoutes() {
-			if _, ok := duplicatedPrefixes[route.Prefix]; !ok {
-				duplicatedPrefixes[route.Prefix] = true
+			_, routeNet, err := net.ParseCIDR(route.GetPrefix())
+			if err != nil {
+				if prefixErr == nil {
+					prefixErr = errors.Wrapf(err, "invalid prefix of the route %v", route)
+				}
+				continue
+			}
+			duplicatedPrefixes[routeNet.String()] = true
+			for _, network := range subtract(routeNet, excluded) {
+				if added[network.String()] {
+					continue
+				}
+				added[network.String()] = true
 				vppagent.Config(ctx).GetLinuxConfig().Routes = append(vppagent.Config(ctx).GetLinuxConfig().Routes, &linux.Route{
-					DstNetwork:        route.Prefix,
+					DstNetwork:        network.String(),
 					OutgoingInterface: vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()[0].GetName(),
 					Scope:             linuxl3.Route_GLOBAL,
 					GwAddr:            extractCleanIPAddress(conn.GetContext().GetIpContext().GetDstIpAddr()),
+					Metric:            s.metric,
 				})
 			}
 		}
 		_, srcNet, err := net.ParseCIDR(conn.GetContext().GetIpContext().GetSrcIpAddr())
 		if err != nil {
-			return
+			return prefixErr
 		}
 		dstIP, dstNet, err := net.ParseCIDR(conn.GetContext().GetIpContext().GetDstIpAddr())
 		if err != nil {
-			return
+			return prefixErr
 		}
 		if _, ok := duplicatedPrefixes[dstNet.String()]; ok || srcNet.Contains(dstIP) {
-			return
+			return prefixErr
 		}
 		if iface := kernelctx.ServerInterface(ctx); iface != nil && dstIP.IsGlobalUnicast() {
+			// The gateway of the routes above has to stay reachable, so its own prefix is never excluded
 			vppagent.Config(ctx).GetLinuxConfig().Routes = append(vppagent.Config(ctx).GetLinuxConfig().Routes, &linux.Route{
 				DstNetwork:        dstNet.String(),
 				OutgoingInterface: iface.GetName(),
 				Scope:             linuxl3.Route_LINK,
+				Metric:            s.metric,
 			})
 		}
 	}
+	return prefixErr
 }
 
 func extractCleanIPAddress(addr string) string {
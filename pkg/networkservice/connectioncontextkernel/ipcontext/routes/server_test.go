@@ -0,0 +1,117 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routes_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+	linuxl3 "go.ligato.io/vpp-agent/v3/proto/ligato/linux/l3"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/routes"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+)
+
+func TestServerExcludedPrefixes(t *testing.T) {
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "id",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: kernel.MECHANISM,
+			},
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					SrcIpAddr: "172.16.1.1/32",
+					DstIpAddr: "172.16.2.1/24",
+					SrcRoutes: []*networkservice.Route{
+						{Prefix: "10.0.0.0/8"},
+						{Prefix: "192.168.0.0/16"},
+					},
+					ExcludedPrefixes: []string{"10.0.0.0/9", "192.168.0.0/16", "172.16.2.0/25"},
+				},
+			},
+		},
+	}
+	ctx := vppagent.WithConfig(context.Background())
+	iface := &linux.Interface{Name: "server-id"}
+	vppagent.Config(ctx).GetLinuxConfig().Interfaces = []*linux.Interface{iface}
+	ctx = kernelctx.WithServerInterface(ctx, iface)
+
+	_, err := routes.NewServer(routes.WithMetric(100)).Request(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, []*linux.Route{
+		{
+			DstNetwork:        "10.128.0.0/9",
+			OutgoingInterface: "server-id",
+			Scope:             linuxl3.Route_GLOBAL,
+			GwAddr:            "172.16.2.1",
+			Metric:            100,
+		},
+		{
+			DstNetwork:        "172.16.2.0/24",
+			OutgoingInterface: "server-id",
+			Scope:             linuxl3.Route_LINK,
+			Metric:            100,
+		},
+	}, vppagent.Config(ctx).GetLinuxConfig().GetRoutes())
+}
+
+func TestServerInvalidPrefix(t *testing.T) {
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "id",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: kernel.MECHANISM,
+			},
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					SrcIpAddr: "172.16.1.1/32",
+					DstIpAddr: "172.16.2.1/24",
+					SrcRoutes: []*networkservice.Route{
+						{Prefix: "10.0.0.0/33"},
+						{Prefix: "192.168.0.0/16"},
+					},
+				},
+			},
+		},
+	}
+	ctx := vppagent.WithConfig(context.Background())
+	iface := &linux.Interface{Name: "server-id"}
+	vppagent.Config(ctx).GetLinuxConfig().Interfaces = []*linux.Interface{iface}
+	ctx = kernelctx.WithServerInterface(ctx, iface)
+
+	_, err := routes.NewServer().Request(ctx, request)
+	require.Error(t, err)
+
+	// The routes of the other prefixes are still removed on Close
+	ctx = vppagent.WithConfig(context.Background())
+	vppagent.Config(ctx).GetLinuxConfig().Interfaces = []*linux.Interface{iface}
+	ctx = kernelctx.WithServerInterface(ctx, iface)
+	_, err = routes.NewServer().Close(ctx, request.GetConnection())
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.0.0/16", vppagent.Config(ctx).GetLinuxConfig().GetRoutes()[0].GetDstNetwork())
+}
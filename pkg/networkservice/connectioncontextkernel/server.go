@@ -21,6 +21,7 @@ import (
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ethernetcontext/arps"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ethernetcontext/macaddress"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/ipaddress"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/routes"
@@ -49,10 +50,14 @@ import (
 //                                            |                           |
 //                                            +---------------------------+
 //
+// Note: the kernel interface is not placed in a linux vrf (l3mdev). vpp-agent v3.1.0 models neither vrf devices nor
+// the enslaving of interfaces to them, and its linux routes have no table to put the routes of routes.NewServer() in,
+// so that needs a newer vpp-agent or netlink.
 func NewServer() networkservice.NetworkServiceServer {
 	return chain.NewNetworkServiceServer(
 		ipaddress.NewServer(),
 		macaddress.NewServer(),
+		arps.NewServer(),
 		// Note: routes are only applicable in this circumstance in the server side
 		routes.NewServer(),
 	)
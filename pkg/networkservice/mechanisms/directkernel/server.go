@@ -0,0 +1,136 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package directkernel provides server chain element that connects two kernel interfaces with a veth pair, bypassing
+// vpp
+package directkernel
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	linuxinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/linux/interfaces"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+)
+
+const (
+	// serverIfacePrefix - prefix the kernel mechanism servers name the interface to the client with
+	serverIfacePrefix = "server-"
+	// clientIfacePrefix - prefix the kernel mechanism clients name the interface to the endpoint with
+	clientIfacePrefix = "client-"
+)
+
+type directKernelServer struct{}
+
+// NewServer creates new direct kernel server.  Rather than vpp xconnecting a kernel interface to the client and a
+// kernel interface to the endpoint, it turns the two into the ends of a single veth pair between their network
+// namespaces, dropping the vpp interfaces so the traffic never enters vpp.  It is not part of the xconnectns chain:
+// a forwarder opting in puts it after connect.NewServer(...), like directmemif.NewServer().
+func NewServer() networkservice.NetworkServiceServer {
+	return &directKernelServer{}
+}
+
+func (d *directKernelServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	if mechanism := kernel.ToMechanism(request.GetConnection().GetMechanism()); mechanism != nil {
+		directVethPair(ctx, request.GetConnection())
+	}
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (d *directKernelServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
+		directVethPair(ctx, conn)
+	}
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+// directVethPair - replaces the kernel interface to the client and the kernel interface to the endpoint with a veth
+//                  pair, if both are backed by a vpp TAP or AF_PACKET interface of the same name
+func directVethPair(ctx context.Context, conn *networkservice.Connection) {
+	conf := vppagent.Config(ctx)
+	clientIface := kernelctx.ServerInterface(ctx)
+	if clientIface == nil || clientIface.GetName() != serverIfacePrefix+conn.GetId() {
+		return
+	}
+	// The connection to the endpoint is the next hop of the Path, and has the ID of its path segment
+	segments := conn.GetPath().GetPathSegments()
+	nextIndex := int(conn.GetPath().GetIndex()) + 1
+	if nextIndex >= len(segments) {
+		return
+	}
+	var endpointIface *linuxinterfaces.Interface
+	linuxIfaces := conf.GetLinuxConfig().GetInterfaces()
+	for _, iface := range linuxIfaces {
+		if iface.GetNamespace() != nil && iface.GetName() == clientIfacePrefix+segments[nextIndex].GetId() {
+			endpointIface = iface
+		}
+	}
+	if endpointIface == nil {
+		return
+	}
+
+	// Only the vpp interfaces named after the two kernel interfaces are dropped, anything else (e.g. an AF_PACKET
+	// interface to a vlan uplink) is left alone
+	var vppIfaces []*vppinterfaces.Interface
+	var dropped int
+	for _, vppIface := range conf.GetVppConfig().GetInterfaces() {
+		if isKernelBackend(vppIface) && (vppIface.GetName() == clientIface.GetName() || vppIface.GetName() == endpointIface.GetName()) {
+			dropped++
+			continue
+		}
+		vppIfaces = append(vppIfaces, vppIface)
+	}
+	if dropped != 2 {
+		return
+	}
+	conf.GetVppConfig().Interfaces = vppIfaces
+
+	// The forwarder side veths of a veth pair backend go along with their vpp AF_PACKET interfaces
+	var kept []*linuxinterfaces.Interface
+	for _, iface := range linuxIfaces {
+		peer := iface.GetVeth().GetPeerIfName()
+		if iface.GetType() == linuxinterfaces.Interface_VETH && (peer == clientIface.GetName() || peer == endpointIface.GetName()) {
+			continue
+		}
+		kept = append(kept, iface)
+	}
+	conf.GetLinuxConfig().Interfaces = kept
+
+	// The interfaces are changed in place, as the chain elements further on may hold on to them
+	toVeth(clientIface, endpointIface.GetName())
+	toVeth(endpointIface, clientIface.GetName())
+}
+
+func isKernelBackend(vppIface *vppinterfaces.Interface) bool {
+	return vppIface.GetType() == vppinterfaces.Interface_TAP || vppIface.GetType() == vppinterfaces.Interface_AF_PACKET
+}
+
+func toVeth(iface *linuxinterfaces.Interface, peerIfName string) {
+	iface.Type = linuxinterfaces.Interface_VETH
+	iface.Link = &linuxinterfaces.Interface_Veth{
+		Veth: &linuxinterfaces.VethLink{
+			PeerIfName: peerIfName,
+		},
+	}
+}
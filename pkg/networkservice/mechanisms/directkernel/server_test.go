@@ -0,0 +1,228 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package directkernel_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+	linuxinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/linux/interfaces"
+	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/directkernel"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+)
+
+func netNS(path string) *linuxnamespace.NetNamespace {
+	return &linuxnamespace.NetNamespace{
+		Type:      linuxnamespace.NetNamespace_FD,
+		Reference: path,
+	}
+}
+
+func testRequest(mechanism *networkservice.Mechanism) *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id:        "id",
+			Mechanism: mechanism,
+			Path: &networkservice.Path{
+				PathSegments: []*networkservice.PathSegment{{Id: "id"}, {Id: "outgoing-id"}},
+			},
+		},
+	}
+}
+
+func kernelMechanism() *networkservice.Mechanism {
+	return &networkservice.Mechanism{
+		Type:       kernel.MECHANISM,
+		Parameters: map[string]string{},
+	}
+}
+
+func TestDirectKernelServer_Tap(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	ctx := vppagent.WithConfig(context.Background())
+	conf := vppagent.Config(ctx)
+	clientIface := &linux.Interface{
+		Name:      "server-id",
+		Type:      linuxinterfaces.Interface_TAP_TO_VPP,
+		Namespace: netNS("/proc/1/ns/net"),
+		Link:      &linuxinterfaces.Interface_Tap{Tap: &linuxinterfaces.TapLink{VppTapIfName: "server-id"}},
+	}
+	endpointIface := &linux.Interface{
+		Name:      "client-outgoing-id",
+		Type:      linuxinterfaces.Interface_TAP_TO_VPP,
+		Namespace: netNS("/proc/2/ns/net"),
+		Link:      &linuxinterfaces.Interface_Tap{Tap: &linuxinterfaces.TapLink{VppTapIfName: "client-outgoing-id"}},
+	}
+	conf.GetLinuxConfig().Interfaces = []*linux.Interface{clientIface, endpointIface}
+	conf.GetVppConfig().Interfaces = []*vpp.Interface{
+		{Name: "server-id", Type: vppinterfaces.Interface_TAP},
+		{Name: "client-outgoing-id", Type: vppinterfaces.Interface_TAP},
+	}
+	ctx = kernelctx.WithServerInterface(ctx, clientIface)
+
+	_, err := directkernel.NewServer().Request(ctx, testRequest(kernelMechanism()))
+	require.NoError(t, err)
+	assert.Empty(t, conf.GetVppConfig().GetInterfaces())
+	require.Len(t, conf.GetLinuxConfig().GetInterfaces(), 2)
+	assert.Equal(t, linuxinterfaces.Interface_VETH, clientIface.GetType())
+	assert.Equal(t, "client-outgoing-id", clientIface.GetVeth().GetPeerIfName())
+	assert.Equal(t, "/proc/1/ns/net", clientIface.GetNamespace().GetReference())
+	assert.Equal(t, linuxinterfaces.Interface_VETH, endpointIface.GetType())
+	assert.Equal(t, "server-id", endpointIface.GetVeth().GetPeerIfName())
+	assert.Equal(t, "/proc/2/ns/net", endpointIface.GetNamespace().GetReference())
+}
+
+func TestDirectKernelServer_VethPair(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	ctx := vppagent.WithConfig(context.Background())
+	conf := vppagent.Config(ctx)
+	veth := func(name, peer string, ns *linuxnamespace.NetNamespace) *linux.Interface {
+		return &linux.Interface{
+			Name:      name,
+			Type:      linuxinterfaces.Interface_VETH,
+			Namespace: ns,
+			Link:      &linuxinterfaces.Interface_Veth{Veth: &linuxinterfaces.VethLink{PeerIfName: peer}},
+		}
+	}
+	clientIface := veth("server-id", "server-id-veth", netNS("/proc/1/ns/net"))
+	endpointIface := veth("client-outgoing-id", "client-outgoing-id-veth", netNS("/proc/2/ns/net"))
+	conf.GetLinuxConfig().Interfaces = []*linux.Interface{
+		veth("server-id-veth", "server-id", nil),
+		clientIface,
+		veth("client-outgoing-id-veth", "client-outgoing-id", nil),
+		endpointIface,
+	}
+	conf.GetVppConfig().Interfaces = []*vpp.Interface{
+		{Name: "server-id", Type: vppinterfaces.Interface_AF_PACKET},
+		{Name: "client-outgoing-id", Type: vppinterfaces.Interface_AF_PACKET},
+	}
+	ctx = kernelctx.WithServerInterface(ctx, clientIface)
+
+	_, err := directkernel.NewServer().Close(ctx, testRequest(kernelMechanism()).GetConnection())
+	require.NoError(t, err)
+	assert.Empty(t, conf.GetVppConfig().GetInterfaces())
+	// Only the namespaced ends are left, now peered with each other
+	assert.Equal(t, []*linux.Interface{clientIface, endpointIface}, conf.GetLinuxConfig().GetInterfaces())
+	assert.Equal(t, "client-outgoing-id", clientIface.GetVeth().GetPeerIfName())
+	assert.Equal(t, "server-id", endpointIface.GetVeth().GetPeerIfName())
+}
+
+func TestDirectKernelServer_NotKernelToKernel(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	ctx := vppagent.WithConfig(context.Background())
+	conf := vppagent.Config(ctx)
+	clientIface := &linux.Interface{
+		Name:      "server-id",
+		Type:      linuxinterfaces.Interface_TAP_TO_VPP,
+		Namespace: netNS("/proc/1/ns/net"),
+	}
+	conf.GetLinuxConfig().Interfaces = []*linux.Interface{clientIface}
+	conf.GetVppConfig().Interfaces = []*vpp.Interface{
+		{Name: "server-id", Type: vppinterfaces.Interface_TAP},
+		{Name: "client-outgoing-id", Type: vppinterfaces.Interface_MEMIF},
+	}
+	ctx = kernelctx.WithServerInterface(ctx, clientIface)
+
+	_, err := directkernel.NewServer().Request(ctx, testRequest(kernelMechanism()))
+	require.NoError(t, err)
+	assert.Len(t, conf.GetVppConfig().GetInterfaces(), 2)
+	assert.Equal(t, linuxinterfaces.Interface_TAP_TO_VPP, clientIface.GetType())
+
+	_, err = directkernel.NewServer().Request(ctx, testRequest(memif.New("/socket")))
+	require.NoError(t, err)
+	assert.Len(t, conf.GetVppConfig().GetInterfaces(), 2)
+}
+
+func TestDirectKernelServer_VlanUplink(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	ctx := vppagent.WithConfig(context.Background())
+	conf := vppagent.Config(ctx)
+	clientIface := &linux.Interface{
+		Name:      "server-id",
+		Type:      linuxinterfaces.Interface_TAP_TO_VPP,
+		Namespace: netNS("/proc/1/ns/net"),
+		Link:      &linuxinterfaces.Interface_Tap{Tap: &linuxinterfaces.TapLink{VppTapIfName: "server-id"}},
+	}
+	conf.GetLinuxConfig().Interfaces = []*linux.Interface{clientIface}
+	// An AF_PACKET interface to a vlan uplink is not the kernel interface to an endpoint
+	conf.GetVppConfig().Interfaces = []*vpp.Interface{
+		{Name: "server-id", Type: vppinterfaces.Interface_TAP},
+		{Name: "eth1", Type: vppinterfaces.Interface_AF_PACKET},
+	}
+	ctx = kernelctx.WithServerInterface(ctx, clientIface)
+
+	_, err := directkernel.NewServer().Request(ctx, testRequest(kernelMechanism()))
+	require.NoError(t, err)
+	assert.Len(t, conf.GetVppConfig().GetInterfaces(), 2)
+	assert.Equal(t, linuxinterfaces.Interface_TAP_TO_VPP, clientIface.GetType())
+
+	// Nor is a namespaced kernel interface without a vpp interface of its own
+	conf.GetLinuxConfig().Interfaces = append(conf.GetLinuxConfig().GetInterfaces(), &linux.Interface{
+		Name:      "client-outgoing-id",
+		Type:      linuxinterfaces.Interface_VETH,
+		Namespace: netNS("/proc/2/ns/net"),
+	})
+	_, err = directkernel.NewServer().Request(ctx, testRequest(kernelMechanism()))
+	require.NoError(t, err)
+	assert.Len(t, conf.GetVppConfig().GetInterfaces(), 2)
+	assert.Equal(t, linuxinterfaces.Interface_TAP_TO_VPP, clientIface.GetType())
+}
+
+func TestDirectKernelServer_OtherConnection(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	ctx := vppagent.WithConfig(context.Background())
+	conf := vppagent.Config(ctx)
+	clientIface := &linux.Interface{
+		Name:      "server-id",
+		Type:      linuxinterfaces.Interface_TAP_TO_VPP,
+		Namespace: netNS("/proc/1/ns/net"),
+		Link:      &linuxinterfaces.Interface_Tap{Tap: &linuxinterfaces.TapLink{VppTapIfName: "server-id"}},
+	}
+	// The kernel interface to the endpoint of another connection is left alone
+	otherIface := &linux.Interface{
+		Name:      "client-other-id",
+		Type:      linuxinterfaces.Interface_TAP_TO_VPP,
+		Namespace: netNS("/proc/2/ns/net"),
+		Link:      &linuxinterfaces.Interface_Tap{Tap: &linuxinterfaces.TapLink{VppTapIfName: "client-other-id"}},
+	}
+	conf.GetLinuxConfig().Interfaces = []*linux.Interface{clientIface, otherIface}
+	conf.GetVppConfig().Interfaces = []*vpp.Interface{
+		{Name: "server-id", Type: vppinterfaces.Interface_TAP},
+		{Name: "client-other-id", Type: vppinterfaces.Interface_TAP},
+	}
+	ctx = kernelctx.WithServerInterface(ctx, clientIface)
+
+	_, err := directkernel.NewServer().Request(ctx, testRequest(kernelMechanism()))
+	require.NoError(t, err)
+	assert.Len(t, conf.GetVppConfig().GetInterfaces(), 2)
+	assert.Equal(t, linuxinterfaces.Interface_TAP_TO_VPP, clientIface.GetType())
+	assert.Equal(t, linuxinterfaces.Interface_TAP_TO_VPP, otherIface.GetType())
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package directmemif
+
+import (
+	"net"
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// memif control messages are 128 bytes long and carry at most one file descriptor, leave plenty of room for both
+	messageSize = 1024
+	maxFDs      = 16
+)
+
+// proxy - relays the memif control channel, file descriptors included, from the clients connecting to its socket file
+//         to the endpoint's socket file, so the memif shared memory is set up between them directly
+type proxy struct {
+	network        string
+	socketFilename string
+	targetFilename string
+	logger         logrus.FieldLogger
+	listener       *net.UnixListener
+
+	mu     sync.Mutex
+	conns  map[*net.UnixConn]struct{}
+	closed bool
+}
+
+// newProxy - starts the proxy listening on socketFilename and relaying to targetFilename
+func newProxy(logger logrus.FieldLogger, network, socketFilename, targetFilename string) (*proxy, error) {
+	if err := os.Remove(socketFilename); err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to remove stale memif socket file %s", socketFilename)
+	}
+	listener, err := net.ListenUnix(network, &net.UnixAddr{Net: network, Name: socketFilename})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to listen on memif socket file %s", socketFilename)
+	}
+	p := &proxy{
+		network:        network,
+		socketFilename: socketFilename,
+		targetFilename: targetFilename,
+		logger:         logger,
+		listener:       listener,
+		conns:          make(map[*net.UnixConn]struct{}),
+	}
+	go p.serve()
+	return p, nil
+}
+
+func (p *proxy) serve() {
+	for {
+		conn, err := p.listener.AcceptUnix()
+		if err != nil {
+			return
+		}
+		target, err := net.DialUnix(p.network, nil, &net.UnixAddr{Net: p.network, Name: p.targetFilename})
+		if err != nil {
+			p.logger.Errorf("failed to connect to memif socket file %s: %+v", p.targetFilename, err)
+			_ = conn.Close()
+			continue
+		}
+		if !p.track(conn, target) {
+			_ = conn.Close()
+			_ = target.Close()
+			return
+		}
+		go p.relay(conn, target)
+		go p.relay(target, conn)
+	}
+}
+
+// relay - copies the messages and the file descriptors sent with them from one end to the other, until either end
+//         is closed
+func (p *proxy) relay(from, to *net.UnixConn) {
+	defer p.untrack(from, to)
+	buf := make([]byte, messageSize)
+	oob := make([]byte, syscall.CmsgSpace(maxFDs*4))
+	for {
+		n, oobn, _, _, err := from.ReadMsgUnix(buf, oob)
+		if err != nil || (n == 0 && oobn == 0) {
+			return
+		}
+		_, _, err = to.WriteMsgUnix(buf[:n], oob[:oobn], nil)
+		// The file descriptors are duplicated by sending them on, so our copies are closed either way
+		closeFDs(oob[:oobn])
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (p *proxy) track(conns ...*net.UnixConn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	for _, conn := range conns {
+		p.conns[conn] = struct{}{}
+	}
+	return true
+}
+
+func (p *proxy) untrack(conns ...*net.UnixConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, conn := range conns {
+		_ = conn.Close()
+		delete(p.conns, conn)
+	}
+}
+
+// Close - stops listening, removing the socket file, and closes all the relayed connections
+func (p *proxy) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	conns := p.conns
+	p.conns = make(map[*net.UnixConn]struct{})
+	p.mu.Unlock()
+	for conn := range conns {
+		_ = conn.Close()
+	}
+	if err := p.listener.Close(); err != nil {
+		return errors.Wrapf(err, "failed to close memif socket file %s", p.socketFilename)
+	}
+	return nil
+}
+
+func closeFDs(oob []byte) {
+	messages, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return
+	}
+	for i := range messages {
+		fds, parseErr := syscall.ParseUnixRights(&messages[i])
+		if parseErr != nil {
+			continue
+		}
+		for _, fd := range fds {
+			_ = syscall.Close(fd)
+		}
+	}
+}
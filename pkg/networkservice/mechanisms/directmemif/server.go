@@ -19,61 +19,112 @@ package directmemif
 
 import (
 	"context"
-	"net/url"
+	"sync"
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
 )
 
-type directMemifServer struct{}
+type directMemifServer struct {
+	network string
+	// proxies - the running proxies, per connection ID
+	proxies sync.Map
+}
 
 // NewServer creates new direct memif server
 func NewServer() networkservice.NetworkServiceServer {
 	return NewServerWithNetwork("unixpacket")
 }
 
-// NewServerWithNetwork creates new direct memif server with specific network
+// NewServerWithNetwork creates new direct memif server with specific network.  Rather than vpp xconnecting a memif to
+// the client and a memif to the endpoint, it runs a proxy over net ("unixpacket" or "unix") on the client's memif
+// socket file, relaying the memif control channel, file descriptors included, to the endpoint's memif socket file.
+// vpp is left in between if the memif parameters agreed on with the client don't match the ones agreed on with the
+// endpoint.
 func NewServerWithNetwork(net string) networkservice.NetworkServiceServer {
-	return &directMemifServer{}
+	return &directMemifServer{
+		network: net,
+	}
 }
 
 func (d *directMemifServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
-	if mechanism := memif.ToMechanism(request.GetConnection().GetMechanism()); mechanism != nil {
-		vc := vppagent.Config(ctx).GetVppConfig()
-		l := len(vc.GetInterfaces())
-		if l < 2 {
-			return next.Server(ctx).Request(ctx, request)
-		}
-		client := vc.GetInterfaces()[l-2]
-		endpoint := vc.GetInterfaces()[l-1]
-		if client.GetMemif() == nil || endpoint.GetMemif() == nil {
+	if memif.ToMechanism(request.GetConnection().GetMechanism()) == nil {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	socketFilename, targetFilename, ok := removeMemifs(ctx)
+	if !ok {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	connID := request.GetConnection().GetId()
+	if value, loaded := d.proxies.Load(connID); loaded {
+		p := value.(*proxy)
+		if p.socketFilename == socketFilename && p.targetFilename == targetFilename {
 			return next.Server(ctx).Request(ctx, request)
 		}
-		vc.Interfaces = vc.GetInterfaces()[:l-2]
-		mechanism.SetSocketFileURL((&url.URL{Scheme: "file", Path: endpoint.GetMemif().GetSocketFilename()}).String())
+		d.closeProxy(ctx, connID)
 	}
-
-	return next.Server(ctx).Request(ctx, request)
+	p, err := newProxy(log.Entry(ctx), d.network, socketFilename, targetFilename)
+	if err != nil {
+		return nil, err
+	}
+	d.proxies.Store(connID, p)
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil {
+		d.closeProxy(ctx, connID)
+		return nil, err
+	}
+	return conn, nil
 }
 
 func (d *directMemifServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
-	if mechanism := memif.ToMechanism(conn.GetMechanism()); mechanism != nil {
-		vc := vppagent.Config(ctx).GetVppConfig()
-		l := len(vc.GetInterfaces())
-		if l < 2 {
-			return next.Server(ctx).Close(ctx, conn)
-		}
-		client := vc.GetInterfaces()[l-2]
-		endpoint := vc.GetInterfaces()[l-1]
-		if client.GetMemif() == nil || endpoint.GetMemif() == nil {
-			return next.Server(ctx).Close(ctx, conn)
+	if memif.ToMechanism(conn.GetMechanism()) != nil {
+		removeMemifs(ctx)
+	}
+	rv, err := next.Server(ctx).Close(ctx, conn)
+	d.closeProxy(ctx, conn.GetId())
+	return rv, err
+}
+
+func (d *directMemifServer) closeProxy(ctx context.Context, connID string) {
+	if value, ok := d.proxies.LoadAndDelete(connID); ok {
+		if err := value.(*proxy).Close(); err != nil {
+			log.Entry(ctx).Warn(err)
 		}
-		vc.Interfaces = vc.GetInterfaces()[:l-2]
-		mechanism.SetSocketFileURL((&url.URL{Scheme: "file", Path: endpoint.GetMemif().GetSocketFilename()}).String())
 	}
-	return next.Server(ctx).Close(ctx, conn)
+}
+
+// removeMemifs - removes the memif to the client and the memif to the endpoint from the vpp config, if those are the
+//                last two interfaces and the client can use the endpoint's memif as it is, and returns their socket
+//                filenames
+func removeMemifs(ctx context.Context) (socketFilename, targetFilename string, ok bool) {
+	vc := vppagent.Config(ctx).GetVppConfig()
+	l := len(vc.GetInterfaces())
+	if l < 2 {
+		return "", "", false
+	}
+	client := vc.GetInterfaces()[l-2].GetMemif()
+	endpoint := vc.GetInterfaces()[l-1].GetMemif()
+	if client == nil || endpoint == nil || !compatible(client, endpoint) {
+		return "", "", false
+	}
+	vc.Interfaces = vc.GetInterfaces()[:l-2]
+	return client.GetSocketFilename(), endpoint.GetSocketFilename(), true
+}
+
+// compatible - returns true if the memif parameters the client agreed on with us match the ones we agreed on with the
+//              endpoint, as the memif control channel is relayed as it is.  The rx and tx queues are relative to the
+//              memif client, and so are flipped on the memif to the client, where we are the master.
+func compatible(client, endpoint *vppinterfaces.MemifLink) bool {
+	return client.GetMode() == endpoint.GetMode() &&
+		client.GetSecret() == endpoint.GetSecret() &&
+		client.GetRingSize() == endpoint.GetRingSize() &&
+		client.GetBufferSize() == endpoint.GetBufferSize() &&
+		client.GetRxQueues() == endpoint.GetTxQueues() &&
+		client.GetTxQueues() == endpoint.GetRxQueues()
 }
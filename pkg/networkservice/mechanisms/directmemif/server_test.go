@@ -0,0 +1,161 @@
+// Copyright (c) 2020 Doc.ai and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package directmemif_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/directmemif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const network = "unixpacket"
+
+func memifInterface(name, socketFilename string, master bool) *vpp.Interface {
+	return &vpp.Interface{
+		Name: name,
+		Type: vppinterfaces.Interface_MEMIF,
+		Link: &vppinterfaces.Interface_Memif{
+			Memif: &vppinterfaces.MemifLink{
+				Master:         master,
+				SocketFilename: socketFilename,
+			},
+		},
+	}
+}
+
+func TestDirectMemifServer(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	dir := t.TempDir()
+	socketFilename := filepath.Join(dir, "client.memif.socket")
+	targetFilename := filepath.Join(dir, "endpoint.memif.socket")
+	endpoint, err := net.ListenUnix(network, &net.UnixAddr{Net: network, Name: targetFilename})
+	require.NoError(t, err)
+	defer func() { _ = endpoint.Close() }()
+
+	ctx := vppagent.WithConfig(context.Background())
+	vppConfig := vppagent.Config(ctx).GetVppConfig()
+	vppConfig.Interfaces = append(vppConfig.Interfaces,
+		memifInterface("server-id", socketFilename, true),
+		memifInterface("client-id", targetFilename, false),
+	)
+	conn := &networkservice.Connection{
+		Id:        "id",
+		Mechanism: memif.New(socketFilename),
+	}
+	serverUnderTest := directmemif.NewServerWithNetwork(network)
+	_, err = serverUnderTest.Request(ctx, &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	// vpp is no longer in between
+	assert.Empty(t, vppConfig.GetInterfaces())
+
+	client, err := net.DialUnix(network, nil, &net.UnixAddr{Net: network, Name: socketFilename})
+	require.NoError(t, err)
+	defer func() { _ = client.Close() }()
+	accepted, err := endpoint.AcceptUnix()
+	require.NoError(t, err)
+	defer func() { _ = accepted.Close() }()
+
+	// Send a file descriptor along with the message, as memif does with its shared memory
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	_, _, err = client.WriteMsgUnix([]byte("hello"), syscall.UnixRights(int(w.Fd())), nil)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	buf := make([]byte, 128)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	n, oobn, _, _, err := accepted.ReadMsgUnix(buf, oob)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf[:n]))
+	messages, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	fds, err := syscall.ParseUnixRights(&messages[0])
+	require.NoError(t, err)
+	require.Len(t, fds, 1)
+	received := os.NewFile(uintptr(fds[0]), "received")
+	_, err = received.Write([]byte("shared"))
+	require.NoError(t, err)
+	require.NoError(t, received.Close())
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "shared", string(data))
+
+	// Messages are relayed back too
+	_, err = accepted.Write([]byte("ack"))
+	require.NoError(t, err)
+	n, err = client.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ack", string(buf[:n]))
+
+	_, err = serverUnderTest.Close(vppagent.WithConfig(context.Background()), conn)
+	require.NoError(t, err)
+	_, err = os.Stat(socketFilename)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDirectMemifServerMismatchedParameters(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	dir := t.TempDir()
+	socketFilename := filepath.Join(dir, "client.memif.socket")
+	targetFilename := filepath.Join(dir, "endpoint.memif.socket")
+
+	ctx := vppagent.WithConfig(context.Background())
+	vppConfig := vppagent.Config(ctx).GetVppConfig()
+	clientMemif := memifInterface("server-id", socketFilename, true)
+	clientMemif.GetMemif().RingSize = 1024
+	endpointMemif := memifInterface("client-id", targetFilename, false)
+	endpointMemif.GetMemif().RingSize = 2048
+	vppConfig.Interfaces = append(vppConfig.Interfaces, clientMemif, endpointMemif)
+	conn := &networkservice.Connection{
+		Id:        "id",
+		Mechanism: memif.New(socketFilename),
+	}
+	serverUnderTest := directmemif.NewServerWithNetwork(network)
+	_, err := serverUnderTest.Request(ctx, &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	// vpp stays in between, with no proxy on the client's socket file
+	assert.Len(t, vppConfig.GetInterfaces(), 2)
+	_, err = os.Stat(socketFilename)
+	assert.True(t, os.IsNotExist(err))
+
+	// and the memifs are closed by vpp too
+	ctx = vppagent.WithConfig(context.Background())
+	vppConfig = vppagent.Config(ctx).GetVppConfig()
+	vppConfig.Interfaces = append(vppConfig.Interfaces, clientMemif, endpointMemif)
+	_, err = serverUnderTest.Close(ctx, conn)
+	require.NoError(t, err)
+	assert.Len(t, vppConfig.GetInterfaces(), 2)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gre provides networkservice chain elements that support the GRE Mechanism for L3 (IP only) payloads
+package gre
+
+import (
+	"context"
+	"net"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/tunnels"
+)
+
+const (
+	// MECHANISM string
+	MECHANISM = "GRE"
+	// SrcIP - Mechanism.Parameters key for the IP the tunnel originates from on the *client* side
+	SrcIP = "src_ip"
+	// DstIP - Mechanism.Parameters key for the IP the tunnel terminates on at the *server* side
+	DstIP = "dst_ip"
+)
+
+type greClient struct {
+	addresses *tunnels.Addresses
+}
+
+// NewClient - returns a NetworkServiceClient chain elements that support the GRE Mechanism
+//             srcNet - network routed to this node and not otherwise used by vpp.  Each connection originates its GRE
+//                      tunnel from an IP of its own in srcNet, so vpp can tell apart the tunnels to the same server.
+func NewClient(srcNet *net.IPNet) networkservice.NetworkServiceClient {
+	return &greClient{
+		addresses: tunnels.NewAddresses(srcNet),
+	}
+}
+
+func (g *greClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	connID := request.GetConnection().GetId()
+	_, refresh := g.addresses.Lookup(connID)
+	srcIP, err := g.addresses.Acquire(connID)
+	if err != nil {
+		return nil, err
+	}
+	preferredMechanism := &networkservice.Mechanism{
+		Cls:  cls.REMOTE,
+		Type: MECHANISM,
+		Parameters: map[string]string{
+			SrcIP: srcIP.String(),
+		},
+	}
+	request.MechanismPreferences = append(request.MechanismPreferences, preferredMechanism)
+	rv, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		if !refresh {
+			g.addresses.Release(connID)
+		}
+		return nil, err
+	}
+	if rv.GetMechanism().GetType() != MECHANISM {
+		g.addresses.Release(connID)
+		return rv, nil
+	}
+	if configErr := appendInterfaceConfig(ctx, rv, true); configErr != nil {
+		if !refresh {
+			g.addresses.Release(connID)
+		}
+		return nil, configErr
+	}
+	return rv, nil
+}
+
+func (g *greClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	configErr := appendInterfaceConfig(ctx, conn, true)
+	g.addresses.Release(conn.GetId())
+	if configErr != nil {
+		return nil, configErr
+	}
+	return rv, nil
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gre_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/checkvppagentmechanism"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/gre"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+func TestGreClient(t *testing.T) {
+	// Turn off log output
+	logrus.SetOutput(ioutil.Discard)
+	srcIP := net.ParseIP("1.1.1.1")
+	dstIP := net.ParseIP("1.1.2.1")
+	_, srcNet, parseErr := net.ParseCIDR("1.1.1.0/24")
+	require.NoError(t, parseErr)
+	testRequest := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "ConnectionId",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.REMOTE,
+				Type: gre.MECHANISM,
+				Parameters: map[string]string{
+					gre.SrcIP: srcIP.String(),
+					gre.DstIP: dstIP.String(),
+				},
+			},
+		},
+	}
+	testConnToClose := testRequest.GetConnection()
+	suite.Run(t, checkvppagentmechanism.NewClientSuite(
+		gre.NewClient(srcNet),
+		gre.MECHANISM,
+		func(t *testing.T, mechanism *networkservice.Mechanism) {
+			require.NotNil(t, mechanism)
+			assert.Equal(t, srcIP.String(), mechanism.GetParameters()[gre.SrcIP])
+		},
+		func(t *testing.T, conf *configurator.Config) { // Check the vppConfig
+			// Basic Checks
+			vppInterfaces := conf.GetVppConfig().GetInterfaces()
+			require.Greater(t, len(vppInterfaces), 0)
+			vppInterface := vppInterfaces[len(vppInterfaces)-1]
+			assert.NotNil(t, vppInterface)
+			assert.Equal(t, vppinterfaces.Interface_GRE_TUNNEL, vppInterface.GetType())
+
+			// Check gre parameters
+			greInterface := vppInterface.GetGre()
+			require.NotNil(t, greInterface)
+			assert.Equal(t, vppinterfaces.GreLink_L3, greInterface.GetTunnelType())
+			assert.Equal(t, srcIP.String(), greInterface.GetSrcAddr())
+			assert.Equal(t, dstIP.String(), greInterface.GetDstAddr())
+		},
+		testRequest,
+		testConnToClose,
+	))
+	t.Run("InvalidDstIP", func(t *testing.T) {
+		req := testRequest.Clone()
+		req.GetConnection().GetMechanism().GetParameters()[gre.DstIP] = InvalidIP
+		clientUnderTest := gre.NewClient(srcNet)
+		conn, err := clientUnderTest.Request(vppagent.WithConfig(context.Background()), req)
+		assert.Nil(t, conn)
+		assert.NotNil(t, err)
+		_, err = clientUnderTest.Close(vppagent.WithConfig(context.Background()), req.GetConnection())
+		assert.NotNil(t, err)
+	})
+	t.Run("TunnelPerConnection", func(t *testing.T) {
+		clientUnderTest := chain.NewNetworkServiceClient(gre.NewClient(srcNet), &selectMechanismClient{dstIP: dstIP})
+		req1 := testRequest.Clone()
+		ctx1 := vppagent.WithConfig(context.Background())
+		conn1, err := clientUnderTest.Request(ctx1, req1)
+		require.NoError(t, err)
+		req2 := testRequest.Clone()
+		req2.GetConnection().Id = "OtherConnectionId"
+		ctx2 := vppagent.WithConfig(context.Background())
+		_, err = clientUnderTest.Request(ctx2, req2)
+		require.NoError(t, err)
+
+		// Each tunnel originates from an IP of its own, which vpp takes in on a loopback
+		vppInterfaces := vppagent.Config(ctx1).GetVppConfig().GetInterfaces()
+		require.Len(t, vppInterfaces, 2)
+		assert.Equal(t, vppinterfaces.Interface_SOFTWARE_LOOPBACK, vppInterfaces[0].GetType())
+		assert.Equal(t, []string{"1.1.1.1/32"}, vppInterfaces[0].GetIpAddresses())
+		assert.Equal(t, "1.1.1.1", vppInterfaces[1].GetGre().GetSrcAddr())
+		assert.Equal(t, dstIP.String(), vppInterfaces[1].GetGre().GetDstAddr())
+		vppInterfaces = vppagent.Config(ctx2).GetVppConfig().GetInterfaces()
+		require.Len(t, vppInterfaces, 2)
+		assert.Equal(t, []string{"1.1.1.2/32"}, vppInterfaces[0].GetIpAddresses())
+		assert.Equal(t, "1.1.1.2", vppInterfaces[1].GetGre().GetSrcAddr())
+
+		// The IP is freed by Close
+		_, err = clientUnderTest.Close(vppagent.WithConfig(context.Background()), conn1)
+		require.NoError(t, err)
+		req3 := testRequest.Clone()
+		req3.GetConnection().Id = "ThirdConnectionId"
+		conn3, err := clientUnderTest.Request(vppagent.WithConfig(context.Background()), req3)
+		require.NoError(t, err)
+		assert.Equal(t, "1.1.1.1", conn3.GetMechanism().GetParameters()[gre.SrcIP])
+	})
+	t.Run("ReleaseOnError", func(t *testing.T) {
+		greClient := gre.NewClient(srcNet)
+		req := testRequest.Clone()
+		_, err := chain.NewNetworkServiceClient(greClient, &errorClient{}).Request(vppagent.WithConfig(context.Background()), req)
+		require.Error(t, err)
+		req = testRequest.Clone()
+		req.GetConnection().Id = "OtherConnectionId"
+		conn, err := chain.NewNetworkServiceClient(greClient, &selectMechanismClient{dstIP: dstIP}).Request(vppagent.WithConfig(context.Background()), req)
+		require.NoError(t, err)
+		assert.Equal(t, "1.1.1.1", conn.GetMechanism().GetParameters()[gre.SrcIP])
+	})
+}
+
+// selectMechanismClient selects the last preferred mechanism the way a server would
+type selectMechanismClient struct {
+	dstIP net.IP
+}
+
+func (s *selectMechanismClient) Request(_ context.Context, request *networkservice.NetworkServiceRequest, _ ...grpc.CallOption) (*networkservice.Connection, error) {
+	mechanism := request.GetMechanismPreferences()[len(request.GetMechanismPreferences())-1].Clone()
+	mechanism.GetParameters()[gre.DstIP] = s.dstIP.String()
+	request.GetConnection().Mechanism = mechanism
+	return request.GetConnection(), nil
+}
+
+func (s *selectMechanismClient) Close(_ context.Context, _ *networkservice.Connection, _ ...grpc.CallOption) (*empty.Empty, error) {
+	return new(empty.Empty), nil
+}
+
+type errorClient struct{}
+
+func (e *errorClient) Request(_ context.Context, _ *networkservice.NetworkServiceRequest, _ ...grpc.CallOption) (*networkservice.Connection, error) {
+	return nil, errors.New("request failed")
+}
+
+func (e *errorClient) Close(_ context.Context, _ *networkservice.Connection, _ ...grpc.CallOption) (*empty.Empty, error) {
+	return nil, errors.New("close failed")
+}
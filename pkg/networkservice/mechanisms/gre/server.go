@@ -0,0 +1,60 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gre
+
+import (
+	"context"
+	"net"
+
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+)
+
+type greServer struct {
+	dstIP net.IP
+}
+
+// NewServer - return a NetworkServiceServer chain elements that support the GRE Mechanism
+//             dstIP - dstIP to use for GRE tunnels
+func NewServer(dstIP net.IP) networkservice.NetworkServiceServer {
+	return &greServer{
+		dstIP: dstIP,
+	}
+}
+
+func (g *greServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	if mechanism := request.GetConnection().GetMechanism(); mechanism.GetType() == MECHANISM {
+		if mechanism.GetParameters() == nil {
+			mechanism.Parameters = make(map[string]string)
+		}
+		mechanism.GetParameters()[DstIP] = g.dstIP.String()
+	}
+	if err := appendInterfaceConfig(ctx, request.GetConnection(), false); err != nil {
+		return nil, err
+	}
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (g *greServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	if err := appendInterfaceConfig(ctx, conn, false); err != nil {
+		return nil, err
+	}
+	return next.Server(ctx).Close(ctx, conn)
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gre_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/checkvppagentmechanism"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/gre"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const (
+	InvalidIP = "Invalid"
+)
+
+func TestGreServer(t *testing.T) {
+	// Turn off log output
+	logrus.SetOutput(ioutil.Discard)
+	srcIP := net.ParseIP("1.1.1.1")
+	dstIP := net.ParseIP("1.1.1.2")
+	testRequest := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "ConnectionId",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.REMOTE,
+				Type: gre.MECHANISM,
+				Parameters: map[string]string{
+					gre.SrcIP: srcIP.String(),
+				},
+			},
+		},
+	}
+	suite.Run(t, checkvppagentmechanism.NewServerSuite(
+		gre.NewServer(dstIP),
+		gre.MECHANISM,
+		func(t *testing.T, mechanism *networkservice.Mechanism) {
+			assert.Equal(t, dstIP.String(), mechanism.GetParameters()[gre.DstIP])
+		},
+		func(t *testing.T, conf *configurator.Config) {
+			// Basic Checks
+			vppInterfaces := conf.GetVppConfig().GetInterfaces()
+			require.Greater(t, len(vppInterfaces), 0)
+			vppInterface := vppInterfaces[len(vppInterfaces)-1]
+			assert.NotNil(t, vppInterface)
+			// Check gre parameters
+			greInterface := vppInterface.GetGre()
+			require.NotNil(t, greInterface)
+			// Note: srcIP and DstIp are relative to the *client*, and so on the server side are flipped
+			assert.Equal(t, dstIP.String(), greInterface.GetSrcAddr())
+			assert.Equal(t, srcIP.String(), greInterface.GetDstAddr())
+		},
+		testRequest,
+		testRequest.GetConnection(),
+	))
+	t.Run("InvalidSrcIP", func(t *testing.T) {
+		req := testRequest.Clone()
+		req.GetConnection().GetMechanism().GetParameters()[gre.SrcIP] = InvalidIP
+		serverUnderTest := gre.NewServer(dstIP)
+		conn, err := serverUnderTest.Request(vppagent.WithConfig(context.Background()), req)
+		assert.Nil(t, conn)
+		assert.NotNil(t, err)
+		_, err = serverUnderTest.Close(vppagent.WithConfig(context.Background()), req.GetConnection())
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ipsec provides networkservice chain elements that support the IPSEC Mechanism: an IPIP tunnel protected by
+// vpp's IPsec tunnel protection using SAs generated per connection by the client
+package ipsec
+
+import (
+	"context"
+	"net"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/tunnelprotection"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/tunnels"
+)
+
+const (
+	// MECHANISM string
+	MECHANISM = "IPSEC"
+	// SrcIP - Mechanism.Parameters key for the IP the tunnel originates from on the *client* side
+	SrcIP = "src_ip"
+	// DstIP - Mechanism.Parameters key for the IP the tunnel terminates on at the *server* side
+	DstIP = "dst_ip"
+)
+
+type ipsecClient struct {
+	addresses *tunnels.Addresses
+}
+
+// NewClient - returns a NetworkServiceClient chain elements that support the IPSEC Mechanism
+//             srcNet - network routed to this node and not otherwise used by vpp.  Each connection originates its
+//                      IPsec tunnel from an IP of its own in srcNet, so vpp can tell apart the tunnels to the same server.
+func NewClient(srcNet *net.IPNet) networkservice.NetworkServiceClient {
+	return &ipsecClient{
+		addresses: tunnels.NewAddresses(srcNet),
+	}
+}
+
+func (i *ipsecClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	connID := request.GetConnection().GetId()
+	_, refresh := i.addresses.Lookup(connID)
+	srcIP, err := i.addresses.Acquire(connID)
+	if err != nil {
+		return nil, err
+	}
+	preferredMechanism := &networkservice.Mechanism{
+		Cls:  cls.REMOTE,
+		Type: MECHANISM,
+		Parameters: map[string]string{
+			SrcIP: srcIP.String(),
+		},
+	}
+	var previous map[string]string
+	if mechanism := request.GetConnection().GetMechanism(); mechanism.GetType() == MECHANISM {
+		previous = mechanism.GetParameters()
+	}
+	if err = tunnelprotection.Generate(preferredMechanism.GetParameters(), previous); err != nil {
+		if !refresh {
+			i.release(connID)
+		}
+		return nil, err
+	}
+	request.MechanismPreferences = append(request.MechanismPreferences, preferredMechanism)
+	rv, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		if !refresh {
+			i.release(connID)
+		}
+		return nil, err
+	}
+	if rv.GetMechanism().GetType() != MECHANISM {
+		i.release(connID)
+		return rv, nil
+	}
+	if configErr := appendInterfaceConfig(ctx, rv, true, false); configErr != nil {
+		if !refresh {
+			i.release(connID)
+		}
+		return nil, configErr
+	}
+	return rv, nil
+}
+
+func (i *ipsecClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	configErr := appendInterfaceConfig(ctx, conn, true, true)
+	i.release(conn.GetId())
+	if configErr != nil {
+		return nil, configErr
+	}
+	return rv, nil
+}
+
+func (i *ipsecClient) release(connID string) {
+	i.addresses.Release(connID)
+	tunnelprotection.Release(connID)
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipsec_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/checkvppagentmechanism"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/ipsec"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/tunnelprotection"
+)
+
+func TestIPSecClient(t *testing.T) {
+	// Turn off log output
+	logrus.SetOutput(ioutil.Discard)
+	srcIP := net.ParseIP("1.1.1.1")
+	dstIP := net.ParseIP("1.1.2.1")
+	_, srcNet, parseErr := net.ParseCIDR("1.1.1.0/24")
+	require.NoError(t, parseErr)
+	testRequest := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "ConnectionId",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.REMOTE,
+				Type: ipsec.MECHANISM,
+				Parameters: map[string]string{
+					ipsec.SrcIP: srcIP.String(),
+					ipsec.DstIP: dstIP.String(),
+				},
+			},
+		},
+	}
+	require.NoError(t, tunnelprotection.Generate(testRequest.GetConnection().GetMechanism().GetParameters(), nil))
+	parameters := testRequest.GetConnection().GetMechanism().GetParameters()
+	testConnToClose := testRequest.GetConnection()
+	suite.Run(t, checkvppagentmechanism.NewClientSuite(
+		ipsec.NewClient(srcNet),
+		ipsec.MECHANISM,
+		func(t *testing.T, mechanism *networkservice.Mechanism) {
+			require.NotNil(t, mechanism)
+			assert.Equal(t, srcIP.String(), mechanism.GetParameters()[ipsec.SrcIP])
+			// The SAs of the connection are reused on refresh
+			for _, key := range []string{tunnelprotection.SrcSPI, tunnelprotection.SrcCryptoKey, tunnelprotection.SrcIntegKey} {
+				assert.Equal(t, parameters[key], mechanism.GetParameters()[key])
+			}
+		},
+		func(t *testing.T, conf *configurator.Config) { // Check the vppConfig
+			// Basic Checks
+			vppInterfaces := conf.GetVppConfig().GetInterfaces()
+			require.Greater(t, len(vppInterfaces), 0)
+			vppInterface := vppInterfaces[len(vppInterfaces)-1]
+			assert.NotNil(t, vppInterface)
+			assert.Equal(t, vppinterfaces.Interface_IPIP_TUNNEL, vppInterface.GetType())
+
+			// Check ipip parameters
+			ipipInterface := vppInterface.GetIpip()
+			require.NotNil(t, ipipInterface)
+			assert.Equal(t, srcIP.String(), ipipInterface.GetSrcAddr())
+			assert.Equal(t, dstIP.String(), ipipInterface.GetDstAddr())
+
+			// Check the SAs protecting the tunnel
+			require.Len(t, conf.GetVppConfig().GetIpsecSas(), 2)
+			outSA, inSA := conf.GetVppConfig().GetIpsecSas()[0], conf.GetVppConfig().GetIpsecSas()[1]
+			assert.Equal(t, parameters[tunnelprotection.SrcCryptoKey], outSA.GetCryptoKey())
+			assert.Equal(t, parameters[tunnelprotection.DstCryptoKey], inSA.GetCryptoKey())
+			require.Len(t, conf.GetVppConfig().GetIpsecTunnelProtections(), 1)
+			protection := conf.GetVppConfig().GetIpsecTunnelProtections()[0]
+			assert.Equal(t, vppInterface.GetName(), protection.GetInterface())
+			assert.Equal(t, []uint32{outSA.GetIndex()}, protection.GetSaOut())
+			assert.Equal(t, []uint32{inSA.GetIndex()}, protection.GetSaIn())
+		},
+		testRequest,
+		testConnToClose,
+	))
+	t.Run("GeneratesSAs", func(t *testing.T) {
+		req := testRequest.Clone()
+		req.GetConnection().GetMechanism().Type = "OTHER"
+		conn, err := ipsec.NewClient(srcNet).Request(vppagent.WithConfig(context.Background()), req)
+		require.NoError(t, err)
+		require.NotNil(t, conn)
+		offered := req.GetMechanismPreferences()[len(req.GetMechanismPreferences())-1].GetParameters()
+		assert.True(t, tunnelprotection.Enabled(offered))
+		assert.NotEqual(t, parameters[tunnelprotection.SrcCryptoKey], offered[tunnelprotection.SrcCryptoKey])
+		assert.NotEqual(t, offered[tunnelprotection.SrcCryptoKey], offered[tunnelprotection.DstCryptoKey])
+	})
+	t.Run("InvalidDstIP", func(t *testing.T) {
+		req := testRequest.Clone()
+		req.GetConnection().GetMechanism().GetParameters()[ipsec.DstIP] = InvalidIP
+		clientUnderTest := ipsec.NewClient(srcNet)
+		conn, err := clientUnderTest.Request(vppagent.WithConfig(context.Background()), req)
+		assert.Nil(t, conn)
+		assert.NotNil(t, err)
+		_, err = clientUnderTest.Close(vppagent.WithConfig(context.Background()), req.GetConnection())
+		assert.NotNil(t, err)
+	})
+}
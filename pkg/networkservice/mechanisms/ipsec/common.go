@@ -0,0 +1,71 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipsec
+
+import (
+	"context"
+	"net"
+
+	"github.com/pkg/errors"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/tunnelprotection"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/tunnels"
+)
+
+func appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection, isClient, isClose bool) error {
+	mechanism := conn.GetMechanism()
+	if mechanism.GetType() != MECHANISM {
+		return nil
+	}
+	srcIP := net.ParseIP(mechanism.GetParameters()[SrcIP])
+	if srcIP == nil {
+		return errors.Errorf("ipsec mechanism parameter %s is not a valid IP: %q", SrcIP, mechanism.GetParameters()[SrcIP])
+	}
+	dstIP := net.ParseIP(mechanism.GetParameters()[DstIP])
+	if dstIP == nil {
+		return errors.Errorf("ipsec mechanism parameter %s is not a valid IP: %q", DstIP, mechanism.GetParameters()[DstIP])
+	}
+	if !tunnelprotection.Enabled(mechanism.GetParameters()) {
+		return errors.New("ipsec mechanism carries no security associations")
+	}
+	// Note: srcIP and dstIP are relative to the *client*, and so on the server side are flipped
+	if !isClient {
+		srcIP, dstIP = dstIP, srcIP
+	}
+	conf := vppagent.Config(ctx)
+	// The client originates each tunnel from an IP of its own, which vpp has to take in
+	if isClient {
+		tunnels.AppendLocalAddress(conf, conn.GetId()+"-src", srcIP)
+	}
+	conf.GetVppConfig().Interfaces = append(conf.GetVppConfig().Interfaces, &vpp.Interface{
+		Name:    conn.GetId(),
+		Type:    vppinterfaces.Interface_IPIP_TUNNEL,
+		Enabled: true,
+		Link: &vppinterfaces.Interface_Ipip{
+			Ipip: &vppinterfaces.IPIPLink{
+				SrcAddr: srcIP.String(),
+				DstAddr: dstIP.String(),
+			},
+		},
+	})
+	return tunnelprotection.AppendConfig(conf, conn.GetId(), mechanism.GetParameters(), isClient, isClose)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipsec
+
+import (
+	"context"
+	"net"
+
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/tunnelprotection"
+)
+
+type ipsecServer struct {
+	dstIP net.IP
+}
+
+// NewServer - return a NetworkServiceServer chain elements that support the IPSEC Mechanism
+//             dstIP - dstIP to use for IPsec tunnels
+func NewServer(dstIP net.IP) networkservice.NetworkServiceServer {
+	return &ipsecServer{
+		dstIP: dstIP,
+	}
+}
+
+func (i *ipsecServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	if mechanism := request.GetConnection().GetMechanism(); mechanism.GetType() == MECHANISM {
+		if mechanism.GetParameters() == nil {
+			mechanism.Parameters = make(map[string]string)
+		}
+		mechanism.GetParameters()[DstIP] = i.dstIP.String()
+	}
+	refresh := tunnelprotection.Acquired(request.GetConnection().GetId())
+	if err := appendInterfaceConfig(ctx, request.GetConnection(), false, false); err != nil {
+		return nil, err
+	}
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil && !refresh {
+		tunnelprotection.Release(request.GetConnection().GetId())
+	}
+	return conn, err
+}
+
+func (i *ipsecServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	if err := appendInterfaceConfig(ctx, conn, false, true); err != nil {
+		return nil, err
+	}
+	rv, err := next.Server(ctx).Close(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	tunnelprotection.Release(conn.GetId())
+	return rv, nil
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipsec_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/checkvppagentmechanism"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/ipsec"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/tunnelprotection"
+)
+
+const (
+	InvalidIP = "Invalid"
+)
+
+func TestIPSecServer(t *testing.T) {
+	// Turn off log output
+	logrus.SetOutput(ioutil.Discard)
+	srcIP := net.ParseIP("1.1.1.1")
+	dstIP := net.ParseIP("1.1.1.2")
+	testRequest := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "ConnectionId",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.REMOTE,
+				Type: ipsec.MECHANISM,
+				Parameters: map[string]string{
+					ipsec.SrcIP: srcIP.String(),
+				},
+			},
+		},
+	}
+	require.NoError(t, tunnelprotection.Generate(testRequest.GetConnection().GetMechanism().GetParameters(), nil))
+	parameters := testRequest.GetConnection().GetMechanism().GetParameters()
+	suite.Run(t, checkvppagentmechanism.NewServerSuite(
+		ipsec.NewServer(dstIP),
+		ipsec.MECHANISM,
+		func(t *testing.T, mechanism *networkservice.Mechanism) {
+			assert.Equal(t, dstIP.String(), mechanism.GetParameters()[ipsec.DstIP])
+		},
+		func(t *testing.T, conf *configurator.Config) {
+			// Basic Checks
+			vppInterfaces := conf.GetVppConfig().GetInterfaces()
+			require.Greater(t, len(vppInterfaces), 0)
+			vppInterface := vppInterfaces[len(vppInterfaces)-1]
+			assert.NotNil(t, vppInterface)
+			// Check ipip parameters
+			ipipInterface := vppInterface.GetIpip()
+			require.NotNil(t, ipipInterface)
+			// Note: srcIP and DstIp are relative to the *client*, and so on the server side are flipped
+			assert.Equal(t, dstIP.String(), ipipInterface.GetSrcAddr())
+			assert.Equal(t, srcIP.String(), ipipInterface.GetDstAddr())
+			// Check the SAs protecting the tunnel, the server sends using the Dst SA and receives using the Src SA
+			require.Len(t, conf.GetVppConfig().GetIpsecSas(), 2)
+			outSA, inSA := conf.GetVppConfig().GetIpsecSas()[0], conf.GetVppConfig().GetIpsecSas()[1]
+			assert.Equal(t, parameters[tunnelprotection.DstCryptoKey], outSA.GetCryptoKey())
+			assert.Equal(t, parameters[tunnelprotection.DstIntegKey], outSA.GetIntegKey())
+			assert.Equal(t, parameters[tunnelprotection.SrcCryptoKey], inSA.GetCryptoKey())
+			assert.Equal(t, parameters[tunnelprotection.SrcIntegKey], inSA.GetIntegKey())
+			require.Len(t, conf.GetVppConfig().GetIpsecTunnelProtections(), 1)
+			protection := conf.GetVppConfig().GetIpsecTunnelProtections()[0]
+			assert.Equal(t, vppInterface.GetName(), protection.GetInterface())
+			assert.Equal(t, []uint32{outSA.GetIndex()}, protection.GetSaOut())
+			assert.Equal(t, []uint32{inSA.GetIndex()}, protection.GetSaIn())
+		},
+		testRequest,
+		testRequest.GetConnection(),
+	))
+	t.Run("MissingSAs", func(t *testing.T) {
+		req := testRequest.Clone()
+		delete(req.GetConnection().GetMechanism().GetParameters(), tunnelprotection.SrcSPI)
+		serverUnderTest := ipsec.NewServer(dstIP)
+		conn, err := serverUnderTest.Request(vppagent.WithConfig(context.Background()), req)
+		assert.Nil(t, conn)
+		assert.NotNil(t, err)
+	})
+	t.Run("InvalidKey", func(t *testing.T) {
+		req := testRequest.Clone()
+		req.GetConnection().GetMechanism().GetParameters()[tunnelprotection.DstCryptoKey] = "Invalid"
+		serverUnderTest := ipsec.NewServer(dstIP)
+		conn, err := serverUnderTest.Request(vppagent.WithConfig(context.Background()), req)
+		assert.Nil(t, conn)
+		assert.NotNil(t, err)
+	})
+	t.Run("DistinctSAIndexes", func(t *testing.T) {
+		serverUnderTest := ipsec.NewServer(dstIP)
+		ctx1 := vppagent.WithConfig(context.Background())
+		conn1, err := serverUnderTest.Request(ctx1, testRequest.Clone())
+		require.NoError(t, err)
+		// The SAs of another connection get other indexes, even with the same SPIs
+		req := testRequest.Clone()
+		req.GetConnection().Id = "OtherConnectionId"
+		ctx2 := vppagent.WithConfig(context.Background())
+		_, err = serverUnderTest.Request(ctx2, req)
+		require.NoError(t, err)
+		indexes := make(map[uint32]bool)
+		for _, sa := range append(vppagent.Config(ctx1).GetVppConfig().GetIpsecSas(), vppagent.Config(ctx2).GetVppConfig().GetIpsecSas()...) {
+			indexes[sa.GetIndex()] = true
+		}
+		assert.Len(t, indexes, 4)
+
+		// A connection keeps its indexes until it is closed
+		closeCtx := vppagent.WithConfig(context.Background())
+		_, err = serverUnderTest.Close(closeCtx, conn1)
+		require.NoError(t, err)
+		assert.Equal(t, vppagent.Config(ctx1).GetVppConfig().GetIpsecSas(), vppagent.Config(closeCtx).GetVppConfig().GetIpsecSas())
+	})
+}
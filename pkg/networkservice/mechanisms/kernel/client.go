@@ -19,13 +19,14 @@
 package kernel
 
 import (
-	"os"
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
-
-	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kerneltap"
-	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kernelvethpair"
 )
 
 const (
@@ -34,10 +35,60 @@ const (
 	vnetFilename = "/dev/vhost-net"
 )
 
+type kernelClient struct {
+	defaultBackend string
+	backends       map[string]networkservice.NetworkServiceClient
+}
+
 // NewClient return a NetworkServiceClient chain element that correctly handles the kernel Mechanism
-func NewClient() networkservice.NetworkServiceClient {
-	if _, err := os.Stat(vnetFilename); err == nil {
-		return kerneltap.NewClient()
+//           opts - options for choosing the backend providing the kernel Mechanism
+func NewClient(opts ...Option) networkservice.NetworkServiceClient {
+	o := applyOptions(opts)
+	return &kernelClient{
+		defaultBackend: o.defaultBackend,
+		backends:       o.clients,
+	}
+}
+
+func (k *kernelClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	name, client, err := k.backend(request.GetConnection())
+	if err != nil {
+		return nil, err
+	}
+	conn, err := client.Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if mechanism := conn.GetMechanism(); mechanism.GetType() == MECHANISM {
+		if mechanism.GetParameters() == nil {
+			mechanism.Parameters = make(map[string]string)
+		}
+		mechanism.GetParameters()[BackendKey] = name
+	}
+	return conn, nil
+}
+
+func (k *kernelClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	_, client, err := k.backend(conn)
+	if err != nil {
+		return nil, err
+	}
+	return client.Close(ctx, conn, opts...)
+}
+
+// backend - returns the backend recorded for conn by a previous Request, or hinted at by its labels
+func (k *kernelClient) backend(conn *networkservice.Connection) (string, networkservice.NetworkServiceClient, error) {
+	var recorded string
+	if conn.GetMechanism().GetType() == MECHANISM {
+		recorded = conn.GetMechanism().GetParameters()[BackendKey]
+	}
+	name := backend(k.defaultBackend, func(name string) bool {
+		_, ok := k.backends[name]
+		return ok
+	}, recorded, conn.GetLabels()[BackendKey])
+	client, ok := k.backends[name]
+	if !ok {
+		return "", nil, errors.Errorf("no kernel mechanism backend %q", name)
 	}
-	return kernelvethpair.NewClient()
+	return name, client, nil
 }
@@ -0,0 +1,82 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package kernel_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	linuxinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/linux/interfaces"
+
+	kernelmech "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/inject/injecterror"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+func TestKernelClient(t *testing.T) {
+	// Turn off log output
+	logrus.SetOutput(ioutil.Discard)
+	t.Run("DefaultBackend", func(t *testing.T) {
+		request := testServerRequest()
+		ctx := vppagent.WithConfig(context.Background())
+		conn, err := kernel.NewClient(kernel.WithDefaultBackend(kernel.TapBackend)).Request(ctx, request)
+		require.NoError(t, err)
+		require.Len(t, request.GetMechanismPreferences(), 1)
+		assert.Equal(t, kernelmech.MECHANISM, request.GetMechanismPreferences()[0].GetType())
+		assert.Equal(t, kernel.TapBackend, conn.GetMechanism().GetParameters()[kernel.BackendKey])
+		linuxInterfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
+		require.Greater(t, len(linuxInterfaces), 0)
+		assert.Equal(t, linuxinterfaces.Interface_TAP_TO_VPP, linuxInterfaces[len(linuxInterfaces)-1].GetType())
+	})
+	t.Run("LabelHint", func(t *testing.T) {
+		request := testServerRequest()
+		request.GetConnection().Labels = map[string]string{kernel.BackendKey: kernel.VethPairBackend}
+		ctx := vppagent.WithConfig(context.Background())
+		conn, err := kernel.NewClient(kernel.WithDefaultBackend(kernel.TapBackend)).Request(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, kernel.VethPairBackend, conn.GetMechanism().GetParameters()[kernel.BackendKey])
+		linuxInterfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
+		require.Greater(t, len(linuxInterfaces), 0)
+		assert.Equal(t, linuxinterfaces.Interface_VETH, linuxInterfaces[len(linuxInterfaces)-1].GetType())
+	})
+	t.Run("RefreshKeepsBackend", func(t *testing.T) {
+		request := testServerRequest()
+		request.GetConnection().GetMechanism().GetParameters()[kernel.BackendKey] = kernel.VethPairBackend
+		request.GetConnection().Labels = map[string]string{kernel.BackendKey: kernel.TapBackend}
+		conn, err := kernel.NewClient(kernel.WithDefaultBackend(kernel.TapBackend)).Request(vppagent.WithConfig(context.Background()), request)
+		require.NoError(t, err)
+		assert.Equal(t, kernel.VethPairBackend, conn.GetMechanism().GetParameters()[kernel.BackendKey])
+	})
+	t.Run("CustomBackend", func(t *testing.T) {
+		clientUnderTest := kernel.NewClient(
+			kernel.WithClientBackend(xdpBackend, injecterror.NewClient()),
+			kernel.WithDefaultBackend(xdpBackend),
+		)
+		_, err := clientUnderTest.Request(vppagent.WithConfig(context.Background()), testServerRequest())
+		assert.NotNil(t, err)
+		_, err = clientUnderTest.Close(vppagent.WithConfig(context.Background()), testServerRequest().GetConnection())
+		assert.NotNil(t, err)
+	})
+}
@@ -29,13 +29,26 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifacename"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
 )
 
-type kernelTapClient struct{}
+type kernelTapClient struct {
+	ifNames    *ifacename.Names
+	namespaces *netnsurl.Namespaces
+	opts       *option
+}
 
-// NewClient provides NetworkServiceClient chain elements that support the kernel Mechanism using tapv2
-func NewClient() networkservice.NetworkServiceClient {
-	return &kernelTapClient{}
+// NewClient provides NetworkServiceClient chain elements that support the kernel Mechanism using tapv2,
+// with the taps configured by opts
+func NewClient(opts ...Option) networkservice.NetworkServiceClient {
+	o := applyOptions(opts)
+	return &kernelTapClient{
+		ifNames:    ifacename.NewNames(),
+		namespaces: netnsurl.NewNamespaces(o.resolver),
+		opts:       o,
+	}
 }
 
 func (k *kernelTapClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
@@ -48,7 +61,7 @@ func (k *kernelTapClient) Request(ctx context.Context, request *networkservice.N
 	if err != nil {
 		return nil, err
 	}
-	if err := appendInterfaceConfig(ctx, conn, fmt.Sprintf("client-%s", conn.GetId())); err != nil {
+	if err := appendInterfaceConfig(ctx, conn, fmt.Sprintf("client-%s", conn.GetId()), k.ifNames, k.namespaces, k.opts, false); err != nil {
 		return nil, err
 	}
 	return conn, nil
@@ -59,9 +72,11 @@ func (k *kernelTapClient) Close(ctx context.Context, conn *networkservice.Connec
 	if err != nil {
 		return nil, err
 	}
-	err = appendInterfaceConfig(ctx, conn, fmt.Sprintf("client-%s", conn.GetId()))
+	err = appendInterfaceConfig(ctx, conn, fmt.Sprintf("client-%s", conn.GetId()), k.ifNames, k.namespaces, k.opts, true)
 	if err != nil {
 		return nil, err
 	}
+	k.ifNames.Release(conn.GetId())
+	k.namespaces.Delete(conn.GetId())
 	return rv, err
 }
@@ -19,10 +19,8 @@ package kerneltap
 
 import (
 	"context"
-	"net/url"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
-	"github.com/pkg/errors"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
 
 	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
@@ -33,28 +31,39 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifacename"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
 )
 
-const (
-	fileScheme = "file"
-)
+// ifNamePrefix - the prefix of the names acquired for the taps in the network namespaces of the connections
+const ifNamePrefix = "nsm"
 
-func appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection, name string) error {
+// appendInterfaceConfig - appends the tap of conn, in the network namespace its NetNSURL resolves to, or on Close in
+//                         the one it was resolved to before
+func appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection, name string, ifNames *ifacename.Names, namespaces *netnsurl.Namespaces, opts *option, isClose bool) error {
 	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
-		netNSURLStr := mechanism.GetNetNSURL()
-		netNSURL, err := url.Parse(netNSURLStr)
+		connOpts, err := opts.withLabels(conn.GetLabels())
 		if err != nil {
 			return err
 		}
-		if netNSURL.Scheme != fileScheme {
-			return errors.Errorf("kernel.ToMechanism(conn.GetMechanism()).GetNetNSURL() must be of scheme %q: %q", fileScheme, netNSURL)
+		resolve := namespaces.Resolve
+		if isClose {
+			resolve = namespaces.Load
+		}
+		netNS, err := resolve(conn.GetId(), mechanism.GetNetNSURL())
+		if err != nil {
+			return err
 		}
-		vppagentConfigTemplate(vppagent.Config(ctx), name, kernel.ToMechanism(conn.GetMechanism()).GetInterfaceName(conn), netNSURL.Path)
+		ifName, err := ifNames.AcquireInterfaceName(ifNamePrefix, conn)
+		if err != nil {
+			return err
+		}
+		vppagentConfigTemplate(vppagent.Config(ctx), name, ifName, netNS, connOpts)
 	}
 	return nil
 }
 
-func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFilename string) {
+func vppagentConfigTemplate(conf *configurator.Config, name, ifName string, netNS *linuxnamespace.NetNamespace, opts *option) {
 	// We append an Interfaces.  Interfaces creates the vpp side of an interface.
 	//   In this case, a Tapv2 interface that has one side in vpp, and the other
 	//   as a Linux kernel interface
@@ -64,7 +73,10 @@ func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFil
 		Enabled: true,
 		Link: &vppinterfaces.Interface_Tap{
 			Tap: &vppinterfaces.TapLink{
-				Version: 2,
+				Version:    2,
+				RxRingSize: opts.rxRingSize,
+				TxRingSize: opts.txRingSize,
+				EnableGso:  opts.gso,
 			},
 		},
 	})
@@ -75,11 +87,9 @@ func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFil
 		Name:       name,
 		Type:       linuxinterfaces.Interface_TAP_TO_VPP,
 		Enabled:    true,
-		HostIfName: linuxIfaceName(ifaceName),
-		Namespace: &linuxnamespace.NetNamespace{
-			Type:      linuxnamespace.NetNamespace_FD,
-			Reference: netnsFilename,
-		},
+		HostIfName: ifName,
+		Mtu:        opts.hostMTU,
+		Namespace:  netNS,
 		Link: &linuxinterfaces.Interface_Tap{
 			Tap: &linuxinterfaces.TapLink{
 				VppTapIfName: name,
@@ -87,10 +97,3 @@ func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFil
 		},
 	})
 }
-
-func linuxIfaceName(ifaceName string) string {
-	if len(ifaceName) <= kernel.LinuxIfMaxLength {
-		return ifaceName
-	}
-	return ifaceName[:kernel.LinuxIfMaxLength]
-}
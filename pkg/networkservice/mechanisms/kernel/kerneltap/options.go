@@ -0,0 +1,128 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kerneltap
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
+)
+
+const (
+	// RxRingSizeKey - Connection.Labels key overriding the rx ring size of the tap for the connection
+	RxRingSizeKey = "kerneltap_rx_ring_size"
+	// TxRingSizeKey - Connection.Labels key overriding the tx ring size of the tap for the connection
+	TxRingSizeKey = "kerneltap_tx_ring_size"
+	// GSOKey - Connection.Labels key overriding whether GSO is enabled on the tap for the connection ("true"/"false")
+	GSOKey = "kerneltap_gso"
+	// HostMTUKey - Connection.Labels key overriding the MTU of the host side of the tap for the connection
+	HostMTUKey = "kerneltap_host_mtu"
+
+	// vpp limits the tap ring sizes to powers of 2 no bigger than this
+	maxRingSize = 32768
+)
+
+type option struct {
+	resolver   *netnsurl.Resolver
+	rxRingSize uint32
+	txRingSize uint32
+	gso        bool
+	hostMTU    uint32
+}
+
+// Option - option for use with kerneltap.NewClient(...) and kerneltap.NewServer(...)
+//          Note: there are no options for the number of queues, GRO, checksum offload or the txqueuelen of the host
+//          side of the taps: the TapLink and LinuxInterface models of vpp-agent v3.1.0 have no fields for them, so
+//          they keep the vpp and kernel defaults (a single queue pair).
+type Option func(opt *option)
+
+// WithRxRingSize - sets the rx ring size of the taps, size must be a power of 2 no bigger than 32768
+func WithRxRingSize(size uint32) Option {
+	return func(opt *option) {
+		opt.rxRingSize = size
+	}
+}
+
+// WithTxRingSize - sets the tx ring size of the taps, size must be a power of 2 no bigger than 32768
+func WithTxRingSize(size uint32) Option {
+	return func(opt *option) {
+		opt.txRingSize = size
+	}
+}
+
+// WithGSO - enables generic segmentation offload on the taps
+func WithGSO() Option {
+	return func(opt *option) {
+		opt.gso = true
+	}
+}
+
+// WithHostMTU - sets the MTU of the host (kernel) side of the taps
+func WithHostMTU(mtu uint32) Option {
+	return func(opt *option) {
+		opt.hostMTU = mtu
+	}
+}
+
+// WithNetNSResolver - sets the resolver of the NetNSURLs of the kernel Mechanisms, the default resolves the schemes
+//                     supported by netnsurl.NewResolver()
+func WithNetNSResolver(resolver *netnsurl.Resolver) Option {
+	return func(opt *option) {
+		opt.resolver = resolver
+	}
+}
+
+func applyOptions(opts []Option) *option {
+	o := &option{
+		resolver: netnsurl.NewResolver(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// withLabels - returns a copy of o overridden by any of the labels of a connection
+func (o *option) withLabels(labels map[string]string) (*option, error) {
+	rv := *o
+	for key, target := range map[string]*uint32{RxRingSizeKey: &rv.rxRingSize, TxRingSizeKey: &rv.txRingSize, HostMTUKey: &rv.hostMTU} {
+		value, ok := labels[key]
+		if !ok {
+			continue
+		}
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, errors.Errorf("label %s has wrong value: %q", key, value)
+		}
+		*target = uint32(parsed)
+	}
+	if value, ok := labels[GSOKey]; ok {
+		gso, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.Errorf("label %s has wrong value: %q", GSOKey, value)
+		}
+		rv.gso = gso
+	}
+	for key, size := range map[string]uint32{RxRingSizeKey: rv.rxRingSize, TxRingSizeKey: rv.txRingSize} {
+		if size != 0 && (size > maxRingSize || size&(size-1) != 0) {
+			return nil, errors.Errorf("%s must be a power of 2 no bigger than %d: %d", key, maxRingSize, size)
+		}
+	}
+	return &rv, nil
+}
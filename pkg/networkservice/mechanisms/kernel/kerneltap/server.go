@@ -28,36 +28,68 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifacename"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
 )
 
-type kernelTapServer struct{}
+type kernelTapServer struct {
+	ifNames    *ifacename.Names
+	namespaces *netnsurl.Namespaces
+	opts       *option
+}
 
-// NewServer provides NetworkServiceServer chain elements that support the kernel Mechanism using tapv2
-func NewServer() networkservice.NetworkServiceServer {
-	return &kernelTapServer{}
+// NewServer provides NetworkServiceServer chain elements that support the kernel Mechanism using tapv2,
+// with the taps configured by opts
+func NewServer(opts ...Option) networkservice.NetworkServiceServer {
+	o := applyOptions(opts)
+	return &kernelTapServer{
+		ifNames:    ifacename.NewNames(),
+		namespaces: netnsurl.NewNamespaces(o.resolver),
+		opts:       o,
+	}
 }
 
 func (k *kernelTapServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
-	if mechanism := kernel.ToMechanism(request.GetConnection().GetMechanism()); mechanism != nil {
-		err := appendInterfaceConfig(ctx, request.GetConnection(), fmt.Sprintf("server-%s", request.GetConnection().GetId()))
-		if err != nil {
-			return nil, err
+	if mechanism := kernel.ToMechanism(request.GetConnection().GetMechanism()); mechanism == nil {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	connID := request.GetConnection().GetId()
+	acquired := k.ifNames.Acquired(connID)
+	loaded := k.namespaces.Loaded(connID)
+	err := appendInterfaceConfig(ctx, request.GetConnection(), fmt.Sprintf("server-%s", connID), k.ifNames, k.namespaces, k.opts, false)
+	if err != nil {
+		return nil, err
+	}
+	linuxIfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
+	ctx = kernelctx.WithServerInterface(ctx, linuxIfaces[len(linuxIfaces)-1])
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil {
+		if !acquired {
+			k.ifNames.Release(connID)
+		}
+		if !loaded {
+			k.namespaces.Delete(connID)
 		}
-		linuxIfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
-		ctx = kernelctx.WithServerInterface(ctx, linuxIfaces[len(linuxIfaces)-1])
 	}
-	return next.Server(ctx).Request(ctx, request)
+	return conn, err
 }
 
 func (k *kernelTapServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
-	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
-		err := appendInterfaceConfig(ctx, conn, fmt.Sprintf("server-%s", conn.GetId()))
-		if err != nil {
-			return nil, err
-		}
-		linuxIfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
-		ctx = kernelctx.WithServerInterface(ctx, linuxIfaces[len(linuxIfaces)-1])
+	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism == nil {
+		return next.Server(ctx).Close(ctx, conn)
+	}
+	err := appendInterfaceConfig(ctx, conn, fmt.Sprintf("server-%s", conn.GetId()), k.ifNames, k.namespaces, k.opts, true)
+	if err != nil {
+		return nil, err
+	}
+	linuxIfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
+	ctx = kernelctx.WithServerInterface(ctx, linuxIfaces[len(linuxIfaces)-1])
+	rv, err := next.Server(ctx).Close(ctx, conn)
+	if err != nil {
+		return nil, err
 	}
-	return next.Server(ctx).Close(ctx, conn)
+	k.ifNames.Release(conn.GetId())
+	k.namespaces.Delete(conn.GetId())
+	return rv, nil
 }
@@ -17,12 +17,16 @@
 package kerneltap_test
 
 import (
+	"context"
 	"io/ioutil"
 	"net/url"
 	"testing"
 
 	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
@@ -30,6 +34,8 @@ import (
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/checkvppagentmechanism"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kerneltap"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
 )
 
 func TestKernelTapServer(t *testing.T) {
@@ -62,4 +68,79 @@ func TestKernelTapServer(t *testing.T) {
 		testRequest,
 		testConnToClose,
 	))
+	t.Run("WithOptions", func(t *testing.T) {
+		serverUnderTest := kerneltap.NewServer(
+			kerneltap.WithRxRingSize(1024),
+			kerneltap.WithTxRingSize(2048),
+			kerneltap.WithGSO(),
+			kerneltap.WithHostMTU(9000),
+		)
+		ctx := vppagent.WithConfig(context.Background())
+		_, err := serverUnderTest.Request(ctx, testRequest.Clone())
+		require.NoError(t, err)
+		tap := vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetTap()
+		assert.Equal(t, uint32(1024), tap.GetRxRingSize())
+		assert.Equal(t, uint32(2048), tap.GetTxRingSize())
+		assert.True(t, tap.GetEnableGso())
+		assert.Equal(t, uint32(9000), vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()[0].GetMtu())
+	})
+	t.Run("LabelOverrides", func(t *testing.T) {
+		req := testRequest.Clone()
+		req.GetConnection().Labels = map[string]string{
+			kerneltap.RxRingSizeKey: "4096",
+			kerneltap.GSOKey:        "false",
+			kerneltap.HostMTUKey:    "1400",
+		}
+		serverUnderTest := kerneltap.NewServer(kerneltap.WithRxRingSize(1024), kerneltap.WithTxRingSize(2048), kerneltap.WithGSO())
+		ctx := vppagent.WithConfig(context.Background())
+		_, err := serverUnderTest.Request(ctx, req)
+		require.NoError(t, err)
+		tap := vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetTap()
+		assert.Equal(t, uint32(4096), tap.GetRxRingSize())
+		assert.Equal(t, uint32(2048), tap.GetTxRingSize())
+		assert.False(t, tap.GetEnableGso())
+		assert.Equal(t, uint32(1400), vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()[0].GetMtu())
+	})
+	t.Run("NetNSURLSchemes", func(t *testing.T) {
+		for netNSURL, expected := range map[string]*linuxnamespace.NetNamespace{
+			"pid://1234":   {Type: linuxnamespace.NetNamespace_PID, Reference: "1234"},
+			"netns://blue": {Type: linuxnamespace.NetNamespace_NSID, Reference: "blue"},
+		} {
+			req := testRequest.Clone()
+			req.GetConnection().GetMechanism().GetParameters()[kernel.NetNSURL] = netNSURL
+			ctx := vppagent.WithConfig(context.Background())
+			_, err := kerneltap.NewServer().Request(ctx, req)
+			require.NoError(t, err)
+			namespace := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()[0].GetNamespace()
+			assert.Equal(t, expected.GetType(), namespace.GetType(), netNSURL)
+			assert.Equal(t, expected.GetReference(), namespace.GetReference(), netNSURL)
+		}
+	})
+	t.Run("UnsupportedNetNSURLScheme", func(t *testing.T) {
+		req := testRequest.Clone()
+		req.GetConnection().GetMechanism().GetParameters()[kernel.NetNSURL] = "tcp://1.1.1.1"
+		conn, err := kerneltap.NewServer().Request(vppagent.WithConfig(context.Background()), req)
+		assert.Nil(t, conn)
+		assert.NotNil(t, err)
+	})
+	t.Run("CustomNetNSURLScheme", func(t *testing.T) {
+		req := testRequest.Clone()
+		req.GetConnection().GetMechanism().GetParameters()[kernel.NetNSURL] = "pod://namespace/name"
+		resolver := netnsurl.NewResolver(netnsurl.WithScheme("pod", func(netNSURL *url.URL) (*linuxnamespace.NetNamespace, error) {
+			return &linuxnamespace.NetNamespace{Type: linuxnamespace.NetNamespace_FD, Reference: netnsFileURL}, nil
+		}))
+		ctx := vppagent.WithConfig(context.Background())
+		_, err := kerneltap.NewServer(kerneltap.WithNetNSResolver(resolver)).Request(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, netnsFileURL, vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()[0].GetNamespace().GetReference())
+	})
+	t.Run("InvalidRingSize", func(t *testing.T) {
+		req := testRequest.Clone()
+		req.GetConnection().Labels = map[string]string{
+			kerneltap.TxRingSizeKey: "1000",
+		}
+		conn, err := kerneltap.NewServer().Request(vppagent.WithConfig(context.Background()), req)
+		assert.Nil(t, conn)
+		assert.NotNil(t, err)
+	})
 }
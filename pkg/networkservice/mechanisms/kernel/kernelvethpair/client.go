@@ -28,13 +28,26 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifacename"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
 )
 
-type kernelVethPairClient struct{}
+type kernelVethPairClient struct {
+	names      *ifacename.Names
+	ifNames    *ifacename.Names
+	namespaces *netnsurl.Namespaces
+}
 
-// NewClient provides NetworkServiceClient chain elements that support the kernel Mechanism using veth pairs
-func NewClient() networkservice.NetworkServiceClient {
-	return &kernelVethPairClient{}
+// NewClient provides NetworkServiceClient chain elements that support the kernel Mechanism using veth pairs,
+// with the NetNSURLs resolved as configured by opts
+func NewClient(opts ...Option) networkservice.NetworkServiceClient {
+	o := applyOptions(opts)
+	return &kernelVethPairClient{
+		names:      ifacename.NewNames(),
+		ifNames:    ifacename.NewNames(),
+		namespaces: netnsurl.NewNamespaces(o.resolver),
+	}
 }
 
 func (k *kernelVethPairClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
@@ -42,12 +55,23 @@ func (k *kernelVethPairClient) Request(ctx context.Context, request *networkserv
 		Cls:  cls.LOCAL,
 		Type: kernel.MECHANISM,
 	}
+	// On refresh keep the interface names the connection already has
+	if mechanism := request.GetConnection().GetMechanism(); mechanism.GetType() == kernel.MECHANISM {
+		for _, key := range []string{HostIfNameKey, kernel.InterfaceNameKey} {
+			if name := mechanism.GetParameters()[key]; name != "" {
+				if preferredMechanism.Parameters == nil {
+					preferredMechanism.Parameters = make(map[string]string)
+				}
+				preferredMechanism.GetParameters()[key] = name
+			}
+		}
+	}
 	request.MechanismPreferences = append(request.MechanismPreferences, preferredMechanism)
 	conn, err := next.Client(ctx).Request(ctx, request, opts...)
 	if err != nil {
 		return nil, err
 	}
-	if err := appendInterfaceConfig(ctx, conn, "client"); err != nil {
+	if err := appendInterfaceConfig(ctx, conn, "client", k.names, k.ifNames, k.namespaces, false); err != nil {
 		return nil, err
 	}
 	return conn, nil
@@ -58,9 +82,12 @@ func (k *kernelVethPairClient) Close(ctx context.Context, conn *networkservice.C
 	if err != nil {
 		return nil, err
 	}
-	err = appendInterfaceConfig(ctx, conn, "client")
+	err = appendInterfaceConfig(ctx, conn, "client", k.names, k.ifNames, k.namespaces, true)
 	if err != nil {
 		return nil, err
 	}
+	k.names.Release(conn.GetId())
+	k.ifNames.Release(conn.GetId())
+	k.namespaces.Delete(conn.GetId())
 	return rv, err
 }
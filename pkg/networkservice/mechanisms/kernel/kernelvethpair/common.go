@@ -19,45 +19,77 @@ package kernelvethpair
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
-	"github.com/pkg/errors"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
 	linuxinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/linux/interfaces"
 	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
 	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifacename"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
 )
 
-const (
-	fileScheme = "file"
-)
+// ifNamePrefix - the prefix of the names acquired for the interfaces in the network namespaces of the connections
+const ifNamePrefix = "nsm"
 
-func appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection, prefix string) error {
+// appendInterfaceConfig - appends the veth pair of conn, in the network namespace its NetNSURL resolves to, or on
+//                         Close in the one it was resolved to before
+func appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection, prefix string, names, ifNames *ifacename.Names, namespaces *netnsurl.Namespaces, isClose bool) error {
 	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
-		netNSURLStr := mechanism.GetNetNSURL()
-		netNSURL, err := url.Parse(netNSURLStr)
+		resolve := namespaces.Resolve
+		if isClose {
+			resolve = namespaces.Load
+		}
+		netNS, err := resolve(conn.GetId(), mechanism.GetNetNSURL())
 		if err != nil {
 			return err
 		}
-		if netNSURL.Scheme != fileScheme {
-			return errors.Errorf("kernel.ToMechanism(conn.GetMechanism()).GetNetNSURL() must be of scheme %q: %q", fileScheme, netNSURL)
+		// The host side of the veth pair lives in the forwarder's namespace, so its name must be unique there
+		hostIfName, err := acquireHostIfName(conn, prefix, names)
+		if err != nil {
+			return err
 		}
-		vppagentConfigTemplate(vppagent.Config(ctx), fmt.Sprintf("%s-%s", prefix, conn.GetId()), kernel.ToMechanism(conn.GetMechanism()).GetInterfaceName(conn), netNSURL.Path)
+		ifName, err := ifNames.AcquireInterfaceName(ifNamePrefix, conn)
+		if err != nil {
+			return err
+		}
+		vppagentConfigTemplate(vppagent.Config(ctx), fmt.Sprintf("%s-%s", prefix, conn.GetId()), hostIfName, ifName, netNS)
 	}
 	return nil
 }
 
-func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFilename string) {
+// acquireHostIfName - returns the name of the host side of the veth pair of conn: the one recorded in its Mechanism,
+//                     which outlives a restart of the forwarder, if it is one names could have given conn, or else a
+//                     newly acquired one, which gets recorded
+func acquireHostIfName(conn *networkservice.Connection, prefix string, names *ifacename.Names) (string, error) {
+	mechanism := conn.GetMechanism()
+	if name := mechanism.GetParameters()[HostIfNameKey]; ifacename.Matches(prefix, conn.GetId(), name) {
+		if err := names.Reserve(conn.GetId(), name); err != nil {
+			return "", err
+		}
+		return name, nil
+	}
+	name, err := names.Acquire(prefix, conn.GetId())
+	if err != nil {
+		return "", err
+	}
+	if mechanism.GetParameters() == nil {
+		mechanism.Parameters = make(map[string]string)
+	}
+	mechanism.GetParameters()[HostIfNameKey] = name
+	return name, nil
+}
+
+func vppagentConfigTemplate(conf *configurator.Config, name, hostIfName, ifName string, netNS *linuxnamespace.NetNamespace) {
 	conf.GetLinuxConfig().Interfaces = append(conf.GetLinuxConfig().Interfaces,
 		&linuxinterfaces.Interface{
 			Name:       name + "-veth",
 			Type:       linuxinterfaces.Interface_VETH,
 			Enabled:    true,
-			HostIfName: linuxIfaceName(name),
+			HostIfName: hostIfName,
 			Link: &linuxinterfaces.Interface_Veth{
 				Veth: &linuxinterfaces.VethLink{
 					PeerIfName:           name,
@@ -70,11 +102,8 @@ func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFil
 			Name:       name,
 			Type:       linuxinterfaces.Interface_VETH,
 			Enabled:    true,
-			HostIfName: linuxIfaceName(ifaceName),
-			Namespace: &linuxnamespace.NetNamespace{
-				Type:      linuxnamespace.NetNamespace_FD,
-				Reference: netnsFilename,
-			},
+			HostIfName: ifName,
+			Namespace:  netNS,
 			Link: &linuxinterfaces.Interface_Veth{
 				Veth: &linuxinterfaces.VethLink{
 					PeerIfName:           name + "-veth",
@@ -89,15 +118,8 @@ func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFil
 		Enabled: true,
 		Link: &vppinterfaces.Interface_Afpacket{
 			Afpacket: &vppinterfaces.AfpacketLink{
-				HostIfName: linuxIfaceName(name),
+				HostIfName: hostIfName,
 			},
 		},
 	})
 }
-
-func linuxIfaceName(ifaceName string) string {
-	if len(ifaceName) <= kernel.LinuxIfMaxLength {
-		return ifaceName
-	}
-	return ifaceName[:kernel.LinuxIfMaxLength]
-}
@@ -0,0 +1,46 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernelvethpair
+
+import (
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
+)
+
+type option struct {
+	resolver *netnsurl.Resolver
+}
+
+// Option - option for use with kernelvethpair.NewClient(...) and kernelvethpair.NewServer(...)
+type Option func(opt *option)
+
+// WithNetNSResolver - sets the resolver of the NetNSURLs of the kernel Mechanisms, the default resolves the schemes
+//                     supported by netnsurl.NewResolver()
+func WithNetNSResolver(resolver *netnsurl.Resolver) Option {
+	return func(opt *option) {
+		opt.resolver = resolver
+	}
+}
+
+func applyOptions(opts []Option) *option {
+	o := &option{
+		resolver: netnsurl.NewResolver(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -28,36 +28,74 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifacename"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
 )
 
-type kernelVethPairServer struct{}
+// HostIfNameKey - Mechanism.Parameters key for the name of the host side of the veth pair of the connection
+const HostIfNameKey = "veth_host_if_name"
 
-// NewServer provides NetworkServiceServer chain elements that support the kernel Mechanism using veth pairs
-func NewServer() networkservice.NetworkServiceServer {
-	return &kernelVethPairServer{}
+type kernelVethPairServer struct {
+	names      *ifacename.Names
+	ifNames    *ifacename.Names
+	namespaces *netnsurl.Namespaces
+}
+
+// NewServer provides NetworkServiceServer chain elements that support the kernel Mechanism using veth pairs,
+// with the NetNSURLs resolved as configured by opts
+func NewServer(opts ...Option) networkservice.NetworkServiceServer {
+	o := applyOptions(opts)
+	return &kernelVethPairServer{
+		names:      ifacename.NewNames(),
+		ifNames:    ifacename.NewNames(),
+		namespaces: netnsurl.NewNamespaces(o.resolver),
+	}
 }
 
 func (k *kernelVethPairServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
-	if mechanism := kernel.ToMechanism(request.GetConnection().GetMechanism()); mechanism != nil {
-		err := appendInterfaceConfig(ctx, request.GetConnection(), "server")
-		if err != nil {
-			return nil, err
+	mechanism := kernel.ToMechanism(request.GetConnection().GetMechanism())
+	if mechanism == nil {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	connID := request.GetConnection().GetId()
+	acquired := k.names.Acquired(connID)
+	loaded := k.namespaces.Loaded(connID)
+	err := appendInterfaceConfig(ctx, request.GetConnection(), "server", k.names, k.ifNames, k.namespaces, false)
+	if err != nil {
+		return nil, err
+	}
+	linuxIfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
+	ctx = kernelctx.WithServerInterface(ctx, linuxIfaces[len(linuxIfaces)-1])
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil {
+		if !acquired {
+			k.names.Release(connID)
+			k.ifNames.Release(connID)
+		}
+		if !loaded {
+			k.namespaces.Delete(connID)
 		}
-		linuxIfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
-		ctx = kernelctx.WithServerInterface(ctx, linuxIfaces[len(linuxIfaces)-1])
 	}
-	return next.Server(ctx).Request(ctx, request)
+	return conn, err
 }
 
 func (k *kernelVethPairServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
-	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
-		err := appendInterfaceConfig(ctx, conn, "server")
-		if err != nil {
-			return nil, err
-		}
-		linuxIfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
-		ctx = kernelctx.WithServerInterface(ctx, linuxIfaces[len(linuxIfaces)-1])
+	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism == nil {
+		return next.Server(ctx).Close(ctx, conn)
+	}
+	err := appendInterfaceConfig(ctx, conn, "server", k.names, k.ifNames, k.namespaces, true)
+	if err != nil {
+		return nil, err
+	}
+	linuxIfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
+	ctx = kernelctx.WithServerInterface(ctx, linuxIfaces[len(linuxIfaces)-1])
+	rv, err := next.Server(ctx).Close(ctx, conn)
+	if err != nil {
+		return nil, err
 	}
-	return next.Server(ctx).Close(ctx, conn)
+	k.names.Release(conn.GetId())
+	k.ifNames.Release(conn.GetId())
+	k.namespaces.Delete(conn.GetId())
+	return rv, nil
 }
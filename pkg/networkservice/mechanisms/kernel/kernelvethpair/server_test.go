@@ -17,6 +17,7 @@
 package kernelvethpair_test
 
 import (
+	"context"
 	"io/ioutil"
 	"net/url"
 	"testing"
@@ -25,10 +26,14 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
 	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/checkvppagentmechanism"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kernelvethpair"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifacename"
 )
 
 func TestKernelVethPairServer(t *testing.T) {
@@ -61,4 +66,51 @@ func TestKernelVethPairServer(t *testing.T) {
 		testRequest,
 		testConnToClose,
 	))
+	t.Run("LongConnectionIDs", func(t *testing.T) {
+		serverUnderTest := kernelvethpair.NewServer()
+		hostIfNames := make(map[string]bool)
+		for _, id := range []string{"ConnectionIdWithALongPrefix-1", "ConnectionIdWithALongPrefix-2"} {
+			req := testRequest.Clone()
+			req.GetConnection().Id = id
+			delete(req.GetConnection().GetMechanism().GetParameters(), kernelvethpair.HostIfNameKey)
+			ctx := vppagent.WithConfig(context.Background())
+			_, err := serverUnderTest.Request(ctx, req)
+			require.NoError(t, err)
+			hostIfName := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()[0].GetHostIfName()
+			assert.LessOrEqual(t, len(hostIfName), kernel.LinuxIfMaxLength)
+			hostIfNames[hostIfName] = true
+		}
+		assert.Len(t, hostIfNames, 2)
+	})
+	t.Run("KeepsRecordedHostIfName", func(t *testing.T) {
+		req := testRequest.Clone()
+		conn, err := kernelvethpair.NewServer().Request(vppagent.WithConfig(context.Background()), req)
+		require.NoError(t, err)
+		hostIfName := conn.GetMechanism().GetParameters()[kernelvethpair.HostIfNameKey]
+		require.NotEmpty(t, hostIfName)
+
+		// A restarted forwarder keeps the name the connection got, a salted one say, even if it would hand out another one
+		names := ifacename.NewNames()
+		require.NoError(t, names.Reserve("OtherConnectionId", hostIfName))
+		salted, err := names.Acquire("server", conn.GetId())
+		require.NoError(t, err)
+		require.NotEqual(t, hostIfName, salted)
+		conn.GetMechanism().GetParameters()[kernelvethpair.HostIfNameKey] = salted
+		ctx := vppagent.WithConfig(context.Background())
+		_, err = kernelvethpair.NewServer().Request(ctx, &networkservice.NetworkServiceRequest{Connection: conn})
+		require.NoError(t, err)
+		assert.Equal(t, salted, vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()[0].GetHostIfName())
+		assert.Equal(t, salted, vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetAfpacket().GetHostIfName())
+	})
+	t.Run("ReplacesForeignHostIfName", func(t *testing.T) {
+		// A name the forwarder could not have given the connection, say one of another connection, is not taken
+		req := testRequest.Clone()
+		req.GetConnection().GetMechanism().GetParameters()[kernelvethpair.HostIfNameKey] = "eth0"
+		ctx := vppagent.WithConfig(context.Background())
+		conn, err := kernelvethpair.NewServer().Request(ctx, req)
+		require.NoError(t, err)
+		hostIfName := conn.GetMechanism().GetParameters()[kernelvethpair.HostIfNameKey]
+		assert.True(t, ifacename.Matches("server", conn.GetId(), hostIfName))
+		assert.Equal(t, hostIfName, vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()[0].GetHostIfName())
+	})
 }
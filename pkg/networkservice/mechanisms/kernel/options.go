@@ -0,0 +1,117 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package kernel
+
+import (
+	"os"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kerneltap"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kernelvethpair"
+)
+
+const (
+	// TapBackend - name of the backend providing the kernel Mechanism using tapv2 (kerneltap)
+	TapBackend = "tap"
+	// VethPairBackend - name of the backend providing the kernel Mechanism using a veth pair and AF_PACKET
+	//                   (kernelvethpair)
+	VethPairBackend = "veth"
+	// BackendKey - Connection.Labels or Mechanism.Parameters key hinting which backend a connection should get.
+	//              The backend the connection actually got is reported under the same key in the Mechanism.Parameters.
+	BackendKey = "kernel_backend"
+)
+
+type option struct {
+	defaultBackend string
+	clients        map[string]networkservice.NetworkServiceClient
+	servers        map[string]networkservice.NetworkServiceServer
+}
+
+// Option - option for use with kernel.NewClient(...) and kernel.NewServer(...)
+type Option func(opt *option)
+
+// WithDefaultBackend - sets the backend used for connections that don't hint at one.  By default TapBackend is used
+//                      if /dev/vhost-net exists and VethPairBackend otherwise.
+func WithDefaultBackend(name string) Option {
+	return func(opt *option) {
+		opt.defaultBackend = name
+	}
+}
+
+// WithClientBackend - adds (or replaces) the client backend called name, used by kernel.NewClient(...)
+func WithClientBackend(name string, client networkservice.NetworkServiceClient) Option {
+	return func(opt *option) {
+		opt.clients[name] = client
+	}
+}
+
+// WithServerBackend - adds (or replaces) the server backend called name, used by kernel.NewServer(...)
+func WithServerBackend(name string, server networkservice.NetworkServiceServer) Option {
+	return func(opt *option) {
+		opt.servers[name] = server
+	}
+}
+
+// WithTapOptions - replaces the built in TapBackend by one whose taps are configured by tapOpts
+func WithTapOptions(tapOpts ...kerneltap.Option) Option {
+	return func(opt *option) {
+		opt.clients[TapBackend] = kerneltap.NewClient(tapOpts...)
+		opt.servers[TapBackend] = kerneltap.NewServer(tapOpts...)
+	}
+}
+
+// WithVethPairOptions - replaces the built in VethPairBackend by one configured by vethPairOpts
+func WithVethPairOptions(vethPairOpts ...kernelvethpair.Option) Option {
+	return func(opt *option) {
+		opt.clients[VethPairBackend] = kernelvethpair.NewClient(vethPairOpts...)
+		opt.servers[VethPairBackend] = kernelvethpair.NewServer(vethPairOpts...)
+	}
+}
+
+func applyOptions(opts []Option) *option {
+	o := &option{
+		defaultBackend: VethPairBackend,
+		clients: map[string]networkservice.NetworkServiceClient{
+			TapBackend:      kerneltap.NewClient(),
+			VethPairBackend: kernelvethpair.NewClient(),
+		},
+		servers: map[string]networkservice.NetworkServiceServer{
+			TapBackend:      kerneltap.NewServer(),
+			VethPairBackend: kernelvethpair.NewServer(),
+		},
+	}
+	if _, err := os.Stat(vnetFilename); err == nil {
+		o.defaultBackend = TapBackend
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// backend - returns the name of the first of hints that is a known backend, the default backend if there is none
+func backend(defaultBackend string, isKnown func(string) bool, hints ...string) string {
+	for _, hint := range hints {
+		if hint != "" && isKnown(hint) {
+			return hint
+		}
+	}
+	return defaultBackend
+}
@@ -21,18 +21,67 @@
 package kernel
 
 import (
-	"os"
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
-	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kerneltap"
-	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kernelvethpair"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 )
 
+type kernelServer struct {
+	defaultBackend string
+	backends       map[string]networkservice.NetworkServiceServer
+}
+
 // NewServer return a NetworkServiceServer chain element that correctly handles the kernel Mechanism
-func NewServer() networkservice.NetworkServiceServer {
-	if _, err := os.Stat(vnetFilename); err == nil {
-		return kerneltap.NewServer()
+//           opts - options for choosing the backend providing the kernel Mechanism
+func NewServer(opts ...Option) networkservice.NetworkServiceServer {
+	o := applyOptions(opts)
+	return &kernelServer{
+		defaultBackend: o.defaultBackend,
+		backends:       o.servers,
+	}
+}
+
+func (k *kernelServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	mechanism := request.GetConnection().GetMechanism()
+	if mechanism.GetType() != MECHANISM {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	name, server, err := k.backend(request.GetConnection())
+	if err != nil {
+		return nil, err
+	}
+	if mechanism.GetParameters() == nil {
+		mechanism.Parameters = make(map[string]string)
+	}
+	mechanism.GetParameters()[BackendKey] = name
+	return server.Request(ctx, request)
+}
+
+func (k *kernelServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	if conn.GetMechanism().GetType() != MECHANISM {
+		return next.Server(ctx).Close(ctx, conn)
+	}
+	_, server, err := k.backend(conn)
+	if err != nil {
+		return nil, err
+	}
+	return server.Close(ctx, conn)
+}
+
+// backend - returns the backend hinted at (or recorded) in the Mechanism.Parameters of conn, or by its labels
+func (k *kernelServer) backend(conn *networkservice.Connection) (string, networkservice.NetworkServiceServer, error) {
+	name := backend(k.defaultBackend, func(name string) bool {
+		_, ok := k.backends[name]
+		return ok
+	}, conn.GetMechanism().GetParameters()[BackendKey], conn.GetLabels()[BackendKey])
+	server, ok := k.backends[name]
+	if !ok {
+		return "", nil, errors.Errorf("no kernel mechanism backend %q", name)
 	}
-	return kernelvethpair.NewServer()
+	return name, server, nil
 }
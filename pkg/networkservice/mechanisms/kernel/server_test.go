@@ -0,0 +1,136 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package kernel_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	linuxinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/linux/interfaces"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	kernelmech "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/inject/injecterror"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kerneltap"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const (
+	netnsFileURL = "/proc/12/ns/net"
+	xdpBackend   = "af_xdp"
+)
+
+func testServerRequest() *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "ConnectionId",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: kernelmech.MECHANISM,
+				Parameters: map[string]string{
+					kernelmech.NetNSURL: (&url.URL{Scheme: "file", Path: netnsFileURL}).String(),
+				},
+			},
+		},
+	}
+}
+
+func TestKernelServer(t *testing.T) {
+	// Turn off log output
+	logrus.SetOutput(ioutil.Discard)
+	t.Run("DefaultBackend", func(t *testing.T) {
+		ctx := vppagent.WithConfig(context.Background())
+		conn, err := kernel.NewServer(kernel.WithDefaultBackend(kernel.VethPairBackend)).Request(ctx, testServerRequest())
+		require.NoError(t, err)
+		assert.Equal(t, kernel.VethPairBackend, conn.GetMechanism().GetParameters()[kernel.BackendKey])
+		linuxInterfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
+		require.Greater(t, len(linuxInterfaces), 0)
+		assert.Equal(t, linuxinterfaces.Interface_VETH, linuxInterfaces[len(linuxInterfaces)-1].GetType())
+	})
+	t.Run("MechanismHint", func(t *testing.T) {
+		request := testServerRequest()
+		request.GetConnection().GetMechanism().GetParameters()[kernel.BackendKey] = kernel.TapBackend
+		ctx := vppagent.WithConfig(context.Background())
+		conn, err := kernel.NewServer(kernel.WithDefaultBackend(kernel.VethPairBackend)).Request(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, kernel.TapBackend, conn.GetMechanism().GetParameters()[kernel.BackendKey])
+		linuxInterfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
+		require.Greater(t, len(linuxInterfaces), 0)
+		assert.Equal(t, linuxinterfaces.Interface_TAP_TO_VPP, linuxInterfaces[len(linuxInterfaces)-1].GetType())
+	})
+	t.Run("LabelHint", func(t *testing.T) {
+		request := testServerRequest()
+		request.GetConnection().Labels = map[string]string{kernel.BackendKey: kernel.TapBackend}
+		ctx := vppagent.WithConfig(context.Background())
+		conn, err := kernel.NewServer(kernel.WithDefaultBackend(kernel.VethPairBackend)).Request(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, kernel.TapBackend, conn.GetMechanism().GetParameters()[kernel.BackendKey])
+	})
+	t.Run("UnknownHint", func(t *testing.T) {
+		request := testServerRequest()
+		request.GetConnection().Labels = map[string]string{kernel.BackendKey: xdpBackend}
+		conn, err := kernel.NewServer(kernel.WithDefaultBackend(kernel.VethPairBackend)).Request(vppagent.WithConfig(context.Background()), request)
+		require.NoError(t, err)
+		assert.Equal(t, kernel.VethPairBackend, conn.GetMechanism().GetParameters()[kernel.BackendKey])
+	})
+	t.Run("CustomBackend", func(t *testing.T) {
+		serverUnderTest := kernel.NewServer(
+			kernel.WithServerBackend(xdpBackend, injecterror.NewServer()),
+			kernel.WithDefaultBackend(xdpBackend),
+		)
+		_, err := serverUnderTest.Request(vppagent.WithConfig(context.Background()), testServerRequest())
+		assert.NotNil(t, err)
+		_, err = serverUnderTest.Close(vppagent.WithConfig(context.Background()), testServerRequest().GetConnection())
+		assert.NotNil(t, err)
+	})
+	t.Run("MissingDefaultBackend", func(t *testing.T) {
+		_, err := kernel.NewServer(kernel.WithDefaultBackend(xdpBackend)).Request(vppagent.WithConfig(context.Background()), testServerRequest())
+		assert.NotNil(t, err)
+	})
+	t.Run("WithTapOptions", func(t *testing.T) {
+		request := testServerRequest()
+		ctx := vppagent.WithConfig(context.Background())
+		_, err := kernel.NewServer(
+			kernel.WithDefaultBackend(kernel.TapBackend),
+			kernel.WithTapOptions(kerneltap.WithRxRingSize(1024)),
+		).Request(ctx, request)
+		require.NoError(t, err)
+		vppInterfaces := vppagent.Config(ctx).GetVppConfig().GetInterfaces()
+		require.Greater(t, len(vppInterfaces), 0)
+		assert.Equal(t, uint32(1024), vppInterfaces[len(vppInterfaces)-1].GetTap().GetRxRingSize())
+	})
+	t.Run("CloseRecordedBackend", func(t *testing.T) {
+		conn := testServerRequest().GetConnection()
+		conn.GetMechanism().GetParameters()[kernel.BackendKey] = kernel.TapBackend
+		ctx := vppagent.WithConfig(context.Background())
+		_, err := kernel.NewServer(kernel.WithDefaultBackend(kernel.VethPairBackend)).Close(ctx, conn)
+		require.NoError(t, err)
+		linuxInterfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
+		require.Greater(t, len(linuxInterfaces), 0)
+		assert.Equal(t, linuxinterfaces.Interface_TAP_TO_VPP, linuxInterfaces[len(linuxInterfaces)-1].GetType())
+	})
+}
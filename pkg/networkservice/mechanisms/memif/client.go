@@ -21,6 +21,8 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"os"
+	"path/filepath"
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/pkg/errors"
@@ -42,11 +44,17 @@ const (
 	MECHANISM = memif.MECHANISM
 )
 
-type memifClient struct{}
+type memifClient struct {
+	opts *option
+}
 
-// NewClient provides a NetworkServiceClient chain elements that support the memif Mechanism
-func NewClient() networkservice.NetworkServiceClient {
-	return &memifClient{}
+// NewClient provides a NetworkServiceClient chain elements that support the memif Mechanism,
+// offering the memif parameters set by opts.  Socket files passed by file descriptor are received by
+// recvfd.NewClient(), which must come further down the chain.
+func NewClient(opts ...Option) networkservice.NetworkServiceClient {
+	return &memifClient{
+		opts: applyOptions(opts),
+	}
 }
 
 func (m *memifClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
@@ -55,12 +63,26 @@ func (m *memifClient) Request(ctx context.Context, request *networkservice.Netwo
 		Type:       memif.MECHANISM,
 		Parameters: make(map[string]string),
 	}
+	for key, value := range m.opts.parameters {
+		mechanism.GetParameters()[key] = value
+	}
+	if m.opts.secret != "" {
+		mechanism.GetParameters()[SecretDigest] = secretDigest(m.opts.secret)
+	}
+	// The parameters of the requested Connection override the options, on refresh they are the ones agreed on before
+	if current := request.GetConnection().GetMechanism(); current.GetType() == memif.MECHANISM {
+		for key, value := range current.GetParameters() {
+			if isOverridable(key) {
+				mechanism.GetParameters()[key] = value
+			}
+		}
+	}
 	request.MechanismPreferences = append(request.MechanismPreferences, mechanism)
 	conn, err := next.Client(ctx).Request(ctx, request, opts...)
 	if err != nil {
 		return nil, err
 	}
-	if err := m.appendInterfaceConfig(ctx, conn); err != nil {
+	if err = m.appendInterfaceConfig(ctx, conn); err != nil {
 		return nil, err
 	}
 	return conn, nil
@@ -87,17 +109,30 @@ func (m *memifClient) appendInterfaceConfig(ctx context.Context, conn *networkse
 		if socketFileURL.Scheme != "file" {
 			return errors.Errorf("url scheme must be 'file' actual: %q", socketFileURL)
 		}
+		if err = checkSecret(conn.GetMechanism().GetParameters(), m.opts.secret); err != nil {
+			return err
+		}
+		link, err := memifLink(conn.GetMechanism().GetParameters(), true, socketFilename(socketFileURL.Path), m.opts.secret)
+		if err != nil {
+			return err
+		}
 		conf.GetVppConfig().Interfaces = append(conf.GetVppConfig().Interfaces, &vpp.Interface{
 			Name:    fmt.Sprintf("client-%s", conn.GetId()),
 			Type:    vppinterfaces.Interface_MEMIF,
 			Enabled: true,
 			Link: &vppinterfaces.Interface_Memif{
-				Memif: &vppinterfaces.MemifLink{
-					Master:         false,
-					SocketFilename: socketFileURL.Path,
-				},
+				Memif: link,
 			},
 		})
 	}
 	return nil
 }
+
+// socketFilename - returns the socket file at path, or in it if path is the directory of its own the socket file is
+//                  in, as passed by file descriptor with WithSocketFDPassing
+func socketFilename(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return filepath.Join(path, socketName)
+	}
+	return path
+}
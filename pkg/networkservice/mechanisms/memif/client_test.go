@@ -17,18 +17,23 @@
 package memif_test
 
 import (
+	"context"
 	"io/ioutil"
+	"net/url"
+	"path/filepath"
 	"testing"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/checkvppagentmechanism"
 	memif_mechanism "github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
 )
 
 func TestMemifClient(t *testing.T) {
@@ -54,4 +59,52 @@ func TestMemifClient(t *testing.T) {
 		testRequest,
 		testRequest.GetConnection(),
 	))
+	t.Run("WithOptions", func(t *testing.T) {
+		req := testRequest.Clone()
+		clientUnderTest := memif_mechanism.NewClient(memif_mechanism.WithRingSize(2048), memif_mechanism.WithQueues(4, 2))
+		ctx := vppagent.WithConfig(context.Background())
+		_, err := clientUnderTest.Request(ctx, req)
+		require.NoError(t, err)
+		// The options are offered to the server
+		require.Len(t, req.GetMechanismPreferences(), 1)
+		offered := req.GetMechanismPreferences()[0].GetParameters()
+		assert.Equal(t, "2048", offered[memif_mechanism.RingSize])
+		assert.Equal(t, "4", offered[memif_mechanism.RxQueues])
+		assert.Equal(t, "2", offered[memif_mechanism.TxQueues])
+	})
+	t.Run("SocketDirectoryURL", func(t *testing.T) {
+		// A socket file passed by file descriptor comes as the directory of its own it is in
+		socketDir := t.TempDir()
+		req := testRequest.Clone()
+		memif.ToMechanism(req.GetConnection().GetMechanism()).SetSocketFileURL((&url.URL{Scheme: "file", Path: socketDir}).String())
+		ctx := vppagent.WithConfig(context.Background())
+		_, err := memif_mechanism.NewClient().Request(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(socketDir, "memif.socket"), vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetMemif().GetSocketFilename())
+	})
+	t.Run("AgreedParameters", func(t *testing.T) {
+		req := testRequest.Clone()
+		req.GetConnection().GetMechanism().GetParameters()[memif_mechanism.RingSize] = "2048"
+		req.GetConnection().GetMechanism().GetParameters()[memif_mechanism.RxQueues] = "4"
+		req.GetConnection().GetMechanism().GetParameters()[memif_mechanism.SecretDigest] = digest("secret")
+		ctx := vppagent.WithConfig(context.Background())
+		_, err := memif_mechanism.NewClient(memif_mechanism.WithRingSize(1024), memif_mechanism.WithSecret("secret")).Request(ctx, req)
+		require.NoError(t, err)
+		// The parameters of the Connection override the options, only the digest of the secret is offered
+		offered := req.GetMechanismPreferences()[0].GetParameters()
+		assert.Equal(t, "2048", offered[memif_mechanism.RingSize])
+		assert.Equal(t, digest("secret"), offered[memif_mechanism.SecretDigest])
+		link := vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetMemif()
+		assert.False(t, link.GetMaster())
+		assert.Equal(t, uint32(2048), link.GetRingSize())
+		assert.Equal(t, uint32(4), link.GetRxQueues())
+		assert.Equal(t, "secret", link.GetSecret())
+	})
+	t.Run("SecretMismatch", func(t *testing.T) {
+		req := testRequest.Clone()
+		req.GetConnection().GetMechanism().GetParameters()[memif_mechanism.SecretDigest] = digest("other")
+		conn, err := memif_mechanism.NewClient(memif_mechanism.WithSecret("secret")).Request(vppagent.WithConfig(context.Background()), req)
+		assert.Nil(t, conn)
+		assert.NotNil(t, err)
+	})
 }
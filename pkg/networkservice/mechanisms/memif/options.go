@@ -0,0 +1,225 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memif
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+)
+
+const (
+	// RingSize - Mechanism.Parameters key for the number of entries of the memif rings, a power of 2
+	RingSize = "ring_size"
+	// BufferSize - Mechanism.Parameters key for the size of the memif buffers in bytes
+	BufferSize = "buffer_size"
+	// RxQueues - Mechanism.Parameters key for the number of queues the *client* receives on
+	RxQueues = "rx_queues"
+	// TxQueues - Mechanism.Parameters key for the number of queues the *client* sends on
+	TxQueues = "tx_queues"
+	// Mode - Mechanism.Parameters key for the memif mode, EthernetMode or IPMode
+	Mode = "mode"
+	// SecretDigest - Mechanism.Parameters key for the hex SHA-256 digest of the secret the memif client and server must
+	//                share.  Only the digest is sent, each side configures its memif with the secret it was given.
+	SecretDigest = "secret_sha256"
+
+	// EthernetMode - memif carrying ethernet frames (default)
+	EthernetMode = "ethernet"
+	// IPMode - memif carrying IP packets
+	IPMode = "ip"
+)
+
+type option struct {
+	parameters map[string]string
+	secret     string
+	dirMode    os.FileMode
+	uid        int
+	gid        int
+	passFD     bool
+}
+
+// Option - option for use with memif.NewClient(...) and memif.NewServer(...)
+//          The client offers its options in the Mechanism.Parameters, overridden by any the Mechanism of the requested
+//          Connection already has.  The server fills in its options for any Mechanism.Parameters the client left out,
+//          so both sides agree, except for the secret, which both sides must have been given.
+type Option func(opt *option)
+
+// WithRingSize - sets the number of entries of the memif rings, size must be a power of 2
+func WithRingSize(size uint32) Option {
+	return func(opt *option) {
+		opt.parameters[RingSize] = strconv.FormatUint(uint64(size), 10)
+	}
+}
+
+// WithBufferSize - sets the size of the memif buffers in bytes
+func WithBufferSize(size uint32) Option {
+	return func(opt *option) {
+		opt.parameters[BufferSize] = strconv.FormatUint(uint64(size), 10)
+	}
+}
+
+// WithQueues - sets the number of queues
+//              rxQueues - number of queues the *client* receives on
+//              txQueues - number of queues the *client* sends on
+func WithQueues(rxQueues, txQueues uint32) Option {
+	return func(opt *option) {
+		opt.parameters[RxQueues] = strconv.FormatUint(uint64(rxQueues), 10)
+		opt.parameters[TxQueues] = strconv.FormatUint(uint64(txQueues), 10)
+	}
+}
+
+// WithIPMode - sets the memifs to carry IP packets rather than ethernet frames
+func WithIPMode() Option {
+	return func(opt *option) {
+		opt.parameters[Mode] = IPMode
+	}
+}
+
+// WithSecret - sets the secret the memif client and server must share, connections where the other side doesn't have
+//              the same secret are rejected
+func WithSecret(secret string) Option {
+	return func(opt *option) {
+		opt.secret = secret
+	}
+}
+
+// WithSocketDirMode - sets the permissions the server creates its baseDir with, the default is 0755
+func WithSocketDirMode(mode os.FileMode) Option {
+	return func(opt *option) {
+		opt.dirMode = mode
+	}
+}
+
+// WithSocketDirOwner - sets the owner the server gives its baseDir, by default it is left to the server's process
+func WithSocketDirOwner(uid, gid int) Option {
+	return func(opt *option) {
+		opt.uid = uid
+		opt.gid = gid
+	}
+}
+
+// WithSocketFDPassing - makes the server pass its socket files to clients by file descriptor, where the connection
+//                       allows for it, so clients don't need to share a volume with it.  A socket file can neither be
+//                       opened, nor be connected to through a file descriptor of its own, but it can through one of
+//                       its directory, as /proc/<pid>/fd/<fd>/<name>.  So each socket file gets a directory of its
+//                       own, and the SocketFileURL refers to that directory, for sendfd.NewServer() further down the
+//                       server's chain to pass.  Clients receive it with recvfd.NewClient() further down their chain
+//                       than memif.NewClient(), which points vpp at the socket file in the directory.
+func WithSocketFDPassing() Option {
+	return func(opt *option) {
+		opt.passFD = true
+	}
+}
+
+func applyOptions(opts []Option) *option {
+	o := &option{
+		parameters: make(map[string]string),
+		dirMode:    defaultDirMode,
+		uid:        -1,
+		gid:        -1,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// negotiate - fills in the options for any of the parameters the client left out, failing if the client asks for a
+//             mode other than the one required by the options, or doesn't have the same secret
+func (o *option) negotiate(parameters map[string]string) error {
+	for key, value := range o.parameters {
+		current, ok := parameters[key]
+		if !ok {
+			parameters[key] = value
+			continue
+		}
+		if key == Mode && current != value {
+			return errors.Errorf("memif mechanism parameter %s does not match the one required by the server", key)
+		}
+	}
+	return checkSecret(parameters, o.secret)
+}
+
+// isOverridable - returns true if key is one of the memif parameters a client may override per connection
+func isOverridable(key string) bool {
+	switch key {
+	case RingSize, BufferSize, RxQueues, TxQueues, Mode:
+		return true
+	}
+	return false
+}
+
+// checkSecret - fails unless the secret digest in parameters is the one of secret, or neither side has a secret
+func checkSecret(parameters map[string]string, secret string) error {
+	digest := parameters[SecretDigest]
+	switch {
+	case secret == "" && digest == "":
+		return nil
+	case secret == "":
+		return errors.Errorf("memif mechanism parameter %s is set, but no secret is configured", SecretDigest)
+	case digest == "":
+		return errors.Errorf("memif mechanism parameter %s is missing, but a secret is required", SecretDigest)
+	case digest != secretDigest(secret):
+		return errors.Errorf("memif mechanism parameter %s does not match the configured secret", SecretDigest)
+	}
+	return nil
+}
+
+func secretDigest(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// memifLink - returns the MemifLink for parameters
+//             isClient - whether we are the client side of the connection, as rx/tx queues are relative to the *client*
+func memifLink(parameters map[string]string, isClient bool, socketFilename, secret string) (*vppinterfaces.MemifLink, error) {
+	link := &vppinterfaces.MemifLink{
+		Master:         !isClient,
+		SocketFilename: socketFilename,
+		Secret:         secret,
+	}
+	switch parameters[Mode] {
+	case "", EthernetMode:
+		link.Mode = vppinterfaces.MemifLink_ETHERNET
+	case IPMode:
+		link.Mode = vppinterfaces.MemifLink_IP
+	default:
+		return nil, errors.Errorf("memif mechanism parameter %s has wrong value: %q", Mode, parameters[Mode])
+	}
+	for key, target := range map[string]*uint32{RingSize: &link.RingSize, BufferSize: &link.BufferSize, RxQueues: &link.RxQueues, TxQueues: &link.TxQueues} {
+		value, ok := parameters[key]
+		if !ok {
+			continue
+		}
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, errors.Errorf("memif mechanism parameter %s has wrong value: %q", key, value)
+		}
+		*target = uint32(parsed)
+	}
+	if link.GetRingSize()&(link.GetRingSize()-1) != 0 {
+		return nil, errors.Errorf("memif mechanism parameter %s must be a power of 2: %d", RingSize, link.GetRingSize())
+	}
+	if !isClient {
+		link.RxQueues, link.TxQueues = link.GetTxQueues(), link.GetRxQueues()
+	}
+	return link, nil
+}
@@ -21,8 +21,11 @@ import (
 	"fmt"
 	"net/url"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/pkg/errors"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
 	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
 
@@ -30,44 +33,127 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
 )
 
 type memifServer struct {
-	baseDir string
+	baseDir  string
+	opts     *option
+	initOnce sync.Once
+	err      error
 }
 
-// NewServer provides a NetworkServiceServer chain elements that support the memif Mechanism
-func NewServer(baseDir string) networkservice.NetworkServiceServer {
-	return &memifServer{baseDir: baseDir}
+// NewServer provides a NetworkServiceServer chain elements that support the memif Mechanism,
+// using the memif parameters set by opts for any the client leaves out.
+// baseDir is where the socket files go, and must be used by this server alone: the socket files left in it are removed
+// when the server is first used.  The socket files are always files in baseDir: vpp-agent v3.1.0 and the VPP releases
+// it supports have no abstract socket mode, so the clients need to share baseDir, or get it with WithSocketFDPassing.
+func NewServer(baseDir string, opts ...Option) networkservice.NetworkServiceServer {
+	return &memifServer{
+		baseDir: baseDir,
+		opts:    applyOptions(opts),
+	}
 }
 
 func (m *memifServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
-	m.appendInterfaceConfig(ctx, request.GetConnection())
+	if err := m.init(); err != nil {
+		return nil, err
+	}
+	if memif.ToMechanism(request.GetConnection().GetMechanism()) != nil && m.opts.passFD {
+		socketFile, err := m.socketFile(request.GetConnection())
+		if err != nil {
+			return nil, err
+		}
+		if err = makeDir(filepath.Dir(socketFile), m.opts); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.appendInterfaceConfig(ctx, request.GetConnection()); err != nil {
+		return nil, err
+	}
 	return next.Server(ctx).Request(ctx, request)
 }
 
 func (m *memifServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
-	m.appendInterfaceConfig(ctx, conn)
-	return next.Server(ctx).Close(ctx, conn)
+	if err := m.init(); err != nil {
+		return nil, err
+	}
+	if err := m.appendInterfaceConfig(ctx, conn); err != nil {
+		return nil, err
+	}
+	rv, err := next.Server(ctx).Close(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	// The memif is gone by now, so its socket file can go too
+	if memif.ToMechanism(conn.GetMechanism()) != nil {
+		// appendInterfaceConfig has checked the socket file
+		socketFile, _ := m.socketFile(conn)
+		if m.opts.passFD {
+			socketFile = filepath.Dir(socketFile)
+		}
+		if removeErr := removeSocketFile(socketFile); removeErr != nil {
+			log.Entry(ctx).Warn(removeErr)
+		}
+	}
+	return rv, nil
+}
+
+// init - prepares baseDir for the socket files on first use
+func (m *memifServer) init() error {
+	m.initOnce.Do(func() {
+		m.err = prepareBaseDir(m.baseDir, m.opts)
+	})
+	return m.err
+}
+
+// socketFile - returns the socket file of conn, named after its ID, failing if the ID would take it out of baseDir
+func (m *memifServer) socketFile(conn *networkservice.Connection) (string, error) {
+	name := conn.GetId() + socketSuffix
+	if m.opts.passFD {
+		// The socket file gets a directory of its own, which is what is passed by file descriptor
+		name = conn.GetId() + socketDirSuffix
+	}
+	entry := filepath.Join(m.baseDir, name)
+	if conn.GetId() == "" || strings.ContainsRune(conn.GetId(), filepath.Separator) || filepath.Dir(entry) != filepath.Clean(m.baseDir) {
+		return "", errors.Errorf("connection ID %q can't name a memif socket file in %s", conn.GetId(), m.baseDir)
+	}
+	if m.opts.passFD {
+		return filepath.Join(entry, socketName), nil
+	}
+	return entry, nil
 }
 
-func (m *memifServer) appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection) {
+func (m *memifServer) appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection) error {
 	if mechanism := memif.ToMechanism(conn.GetMechanism()); mechanism != nil {
 		conf := vppagent.Config(ctx)
-		socketFile := filepath.Join(m.baseDir, fmt.Sprintf("%s.memif.socket", conn.GetId()))
-		mechanism.SetSocketFileURL((&url.URL{Scheme: memif.SocketFileScheme, Path: socketFile}).String())
+		socketFile, err := m.socketFile(conn)
+		if err != nil {
+			return err
+		}
+		socketFileURL := &url.URL{Scheme: memif.SocketFileScheme, Path: socketFile}
+		if m.opts.passFD {
+			// sendfd.NewServer() passes the file the URL refers to, which can't be the socket file itself
+			socketFileURL.Path = filepath.Dir(socketFile)
+		}
+		mechanism.SetSocketFileURL(socketFileURL.String())
+		if err = m.opts.negotiate(conn.GetMechanism().GetParameters()); err != nil {
+			return err
+		}
+		link, err := memifLink(conn.GetMechanism().GetParameters(), false, socketFile, m.opts.secret)
+		if err != nil {
+			return err
+		}
 		conf.GetVppConfig().Interfaces = append(conf.GetVppConfig().Interfaces, &vpp.Interface{
 			Name:    fmt.Sprintf("server-%s", conn.GetId()),
 			Type:    vppinterfaces.Interface_MEMIF,
 			Enabled: true,
 			Link: &vppinterfaces.Interface_Memif{
-				Memif: &vppinterfaces.MemifLink{
-					Master:         true,
-					SocketFilename: socketFile,
-				},
+				Memif: link,
 			},
 		})
 	}
+	return nil
 }
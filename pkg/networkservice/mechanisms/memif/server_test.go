@@ -17,33 +17,41 @@
 package memif_test
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/url"
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/checkvppagentmechanism"
 	memif_mechanism "github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
 )
 
 const (
 	SocketFilename = "/foo"
-	BaseDir        = "baseDir"
 	ID             = "testId"
 )
 
 func TestMemifServer(t *testing.T) {
 	logrus.SetOutput(ioutil.Discard)
+	baseDir := filepath.Join(t.TempDir(), "baseDir")
 	testRequest := &networkservice.NetworkServiceRequest{
 		Connection: &networkservice.Connection{
 			Id: ID,
@@ -55,12 +63,12 @@ func TestMemifServer(t *testing.T) {
 	}
 	suite.Run(t,
 		checkvppagentmechanism.NewServerSuite(
-			memif_mechanism.NewServer(BaseDir),
+			memif_mechanism.NewServer(baseDir),
 			memif.MECHANISM,
 			func(t *testing.T, mechanism *networkservice.Mechanism) {
 				m := memif.ToMechanism(mechanism)
 				assert.NotNil(t, m)
-				assert.Equal(t, (&url.URL{Scheme: "file", Path: filepath.Join(BaseDir, fmt.Sprintf("%s.memif.socket", ID))}).String(), m.GetSocketFileURL())
+				assert.Equal(t, (&url.URL{Scheme: "file", Path: filepath.Join(baseDir, fmt.Sprintf("%s.memif.socket", ID))}).String(), m.GetSocketFileURL())
 			},
 			func(t *testing.T, conf *configurator.Config) {
 				numInterfaces := len(conf.GetVppConfig().GetInterfaces())
@@ -69,10 +77,158 @@ func TestMemifServer(t *testing.T) {
 				assert.NotNil(t, iface)
 				ifaceMemif := conf.GetVppConfig().GetInterfaces()[numInterfaces-1].GetMemif()
 				assert.NotNil(t, iface)
-				assert.Equal(t, filepath.Join(BaseDir, fmt.Sprintf("%s.memif.socket", ID)), ifaceMemif.GetSocketFilename())
+				assert.Equal(t, filepath.Join(baseDir, fmt.Sprintf("%s.memif.socket", ID)), ifaceMemif.GetSocketFilename())
 			},
 			testRequest,
 			testRequest.GetConnection(),
 		),
 	)
+	t.Run("Negotiate", func(t *testing.T) {
+		req := testRequest.Clone()
+		req.GetConnection().GetMechanism().Parameters = map[string]string{
+			memif_mechanism.RingSize:     "4096",
+			memif_mechanism.RxQueues:     "4",
+			memif_mechanism.TxQueues:     "2",
+			memif_mechanism.SecretDigest: digest("secret"),
+		}
+		serverUnderTest := memif_mechanism.NewServer(baseDir,
+			memif_mechanism.WithRingSize(1024),
+			memif_mechanism.WithBufferSize(4096),
+			memif_mechanism.WithIPMode(),
+			memif_mechanism.WithSecret("secret"),
+		)
+		ctx := vppagent.WithConfig(context.Background())
+		conn, err := serverUnderTest.Request(ctx, req)
+		require.NoError(t, err)
+		// The agreed parameters are returned to the client
+		parameters := conn.GetMechanism().GetParameters()
+		assert.Equal(t, "4096", parameters[memif_mechanism.RingSize])
+		assert.Equal(t, "4096", parameters[memif_mechanism.BufferSize])
+		assert.Equal(t, memif_mechanism.IPMode, parameters[memif_mechanism.Mode])
+		// The secret itself is never sent
+		assert.Equal(t, digest("secret"), parameters[memif_mechanism.SecretDigest])
+		for _, value := range parameters {
+			assert.NotEqual(t, "secret", value)
+		}
+
+		link := vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetMemif()
+		assert.True(t, link.GetMaster())
+		assert.Equal(t, uint32(4096), link.GetRingSize())
+		assert.Equal(t, uint32(4096), link.GetBufferSize())
+		assert.Equal(t, vppinterfaces.MemifLink_IP, link.GetMode())
+		assert.Equal(t, "secret", link.GetSecret())
+		// Note: rx and tx queues are relative to the *client*, and so on the server side are flipped
+		assert.Equal(t, uint32(2), link.GetRxQueues())
+		assert.Equal(t, uint32(4), link.GetTxQueues())
+	})
+	t.Run("SecretMismatch", func(t *testing.T) {
+		for _, tc := range []struct {
+			name         string
+			secret       string
+			secretDigest string
+		}{
+			{name: "Mismatched", secret: "secret", secretDigest: digest("other")},
+			{name: "Missing", secret: "secret"},
+			{name: "Unexpected", secretDigest: digest("secret")},
+		} {
+			req := testRequest.Clone()
+			req.GetConnection().GetMechanism().Parameters = map[string]string{}
+			if tc.secretDigest != "" {
+				req.GetConnection().GetMechanism().GetParameters()[memif_mechanism.SecretDigest] = tc.secretDigest
+			}
+			var opts []memif_mechanism.Option
+			if tc.secret != "" {
+				opts = append(opts, memif_mechanism.WithSecret(tc.secret))
+			}
+			conn, err := memif_mechanism.NewServer(baseDir, opts...).Request(vppagent.WithConfig(context.Background()), req)
+			assert.Nil(t, conn, tc.name)
+			assert.NotNil(t, err, tc.name)
+		}
+	})
+	t.Run("SocketDir", func(t *testing.T) {
+		socketDir := filepath.Join(t.TempDir(), "socketDir")
+		require.NoError(t, os.MkdirAll(socketDir, 0777))
+		staleSocketFile := filepath.Join(socketDir, "stale.memif.socket")
+		require.NoError(t, ioutil.WriteFile(staleSocketFile, nil, 0600))
+		otherFile := filepath.Join(socketDir, "other")
+		require.NoError(t, ioutil.WriteFile(otherFile, nil, 0600))
+
+		serverUnderTest := memif_mechanism.NewServer(socketDir, memif_mechanism.WithSocketDirMode(0750))
+		_, err := serverUnderTest.Request(vppagent.WithConfig(context.Background()), testRequest.Clone())
+		require.NoError(t, err)
+		fileInfo, err := os.Stat(socketDir)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0750), fileInfo.Mode().Perm())
+		// Socket files left by a previous run are removed, other files are not
+		_, err = os.Stat(staleSocketFile)
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(otherFile)
+		assert.NoError(t, err)
+	})
+	t.Run("RemovesSocketOnClose", func(t *testing.T) {
+		socketDir := t.TempDir()
+		serverUnderTest := memif_mechanism.NewServer(socketDir)
+		conn, err := serverUnderTest.Request(vppagent.WithConfig(context.Background()), testRequest.Clone())
+		require.NoError(t, err)
+		// vpp would create the socket file
+		socketFile := filepath.Join(socketDir, fmt.Sprintf("%s.memif.socket", ID))
+		require.NoError(t, ioutil.WriteFile(socketFile, nil, 0600))
+		_, err = serverUnderTest.Close(vppagent.WithConfig(context.Background()), conn)
+		require.NoError(t, err)
+		_, err = os.Stat(socketFile)
+		assert.True(t, os.IsNotExist(err))
+	})
+	t.Run("SocketFDPassing", func(t *testing.T) {
+		socketDir := t.TempDir()
+		serverUnderTest := memif_mechanism.NewServer(socketDir, memif_mechanism.WithSocketFDPassing())
+		conn, err := serverUnderTest.Request(vppagent.WithConfig(context.Background()), testRequest.Clone())
+		require.NoError(t, err)
+		// The URL refers to the directory of its own the socket file is in, for sendfd to pass
+		socketFile := filepath.Join(socketDir, fmt.Sprintf("%s.memif", ID), "memif.socket")
+		assert.Equal(t, (&url.URL{Scheme: "file", Path: filepath.Dir(socketFile)}).String(), memif.ToMechanism(conn.GetMechanism()).GetSocketFileURL())
+
+		// vpp would listen on the socket file, which can be connected to through the file descriptor of its directory
+		listener, err := net.Listen("unix", socketFile)
+		require.NoError(t, err)
+		defer func() { _ = listener.Close() }()
+		dir, err := os.Open(filepath.Dir(socketFile))
+		require.NoError(t, err)
+		defer func() { _ = dir.Close() }()
+		socketConn, err := net.Dial("unix", fmt.Sprintf("/proc/%d/fd/%d/memif.socket", os.Getpid(), dir.Fd()))
+		require.NoError(t, err)
+		_ = socketConn.Close()
+
+		// The directory goes along with the socket file
+		_, err = serverUnderTest.Close(vppagent.WithConfig(context.Background()), conn)
+		require.NoError(t, err)
+		_, err = os.Stat(filepath.Dir(socketFile))
+		assert.True(t, os.IsNotExist(err))
+	})
+	t.Run("InvalidRingSize", func(t *testing.T) {
+		req := testRequest.Clone()
+		req.GetConnection().GetMechanism().Parameters = map[string]string{
+			memif_mechanism.RingSize: "1000",
+		}
+		conn, err := memif_mechanism.NewServer(baseDir).Request(vppagent.WithConfig(context.Background()), req)
+		assert.Nil(t, conn)
+		assert.NotNil(t, err)
+	})
+	t.Run("ConnectionIDOutOfBaseDir", func(t *testing.T) {
+		// The connection ID comes from the client, it must not get the socket file out of baseDir
+		for _, opts := range [][]memif_mechanism.Option{nil, {memif_mechanism.WithSocketFDPassing()}} {
+			req := testRequest.Clone()
+			req.GetConnection().Id = "../../escaped"
+			conn, err := memif_mechanism.NewServer(baseDir, opts...).Request(vppagent.WithConfig(context.Background()), req)
+			assert.Nil(t, conn)
+			assert.NotNil(t, err)
+			_, err = os.Stat(filepath.Join(baseDir, "..", "..", "escaped.memif"))
+			assert.True(t, os.IsNotExist(err))
+		}
+	})
+}
+
+// digest - returns the SecretDigest of secret
+func digest(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
 }
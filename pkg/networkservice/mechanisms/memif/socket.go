@@ -0,0 +1,77 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memif
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	socketSuffix = ".memif.socket"
+	// socketDirSuffix - suffix of the directories of their own socket files get when they are passed by file descriptor
+	socketDirSuffix = ".memif"
+	// socketName - name of the socket files in directories of their own
+	socketName     = "memif.socket"
+	defaultDirMode = 0755
+)
+
+// prepareBaseDir - creates baseDir with the mode and owner set by opts, and removes the socket files left in it by a
+//                  previous run.  baseDir is the server's alone, so any socket file in it is one of its own.
+func prepareBaseDir(baseDir string, opts *option) error {
+	if err := makeDir(baseDir, opts); err != nil {
+		return err
+	}
+	for _, pattern := range []string{"*" + socketSuffix, "*" + socketDirSuffix} {
+		stale, err := filepath.Glob(filepath.Join(baseDir, pattern))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for _, socketFile := range stale {
+			if err := removeSocketFile(socketFile); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// makeDir - creates dir with the mode and owner set by opts
+func makeDir(dir string, opts *option) error {
+	if err := os.MkdirAll(dir, opts.dirMode); err != nil {
+		return errors.Wrapf(err, "failed to create memif socket directory %s", dir)
+	}
+	if err := os.Chmod(dir, opts.dirMode); err != nil {
+		return errors.Wrapf(err, "failed to set the permissions of memif socket directory %s", dir)
+	}
+	if opts.uid != -1 || opts.gid != -1 {
+		if err := os.Chown(dir, opts.uid, opts.gid); err != nil {
+			return errors.Wrapf(err, "failed to set the owner of memif socket directory %s", dir)
+		}
+	}
+	return nil
+}
+
+// removeSocketFile - removes socketFile, or the directory of its own it is in, if it is still there
+func removeSocketFile(socketFile string) error {
+	if err := os.RemoveAll(socketFile); err != nil {
+		return errors.Wrapf(err, "failed to remove memif socket file %s", socketFile)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vlan
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// VLAN IDs 0 and 4095 are reserved by 802.1Q
+	minVlanID = 1
+	maxVlanID = 4094
+)
+
+// allocator hands out the VLAN IDs of an uplink, making sure each of them is owned by at most one connection
+type allocator struct {
+	owners map[uint32]string
+	ids    map[string]uint32
+	mu     sync.Mutex
+}
+
+func newAllocator() *allocator {
+	return &allocator{
+		owners: make(map[uint32]string),
+		ids:    make(map[string]uint32),
+	}
+}
+
+// acquire - returns the VLAN ID in [minID, maxID] owned by connID.  If vlanID is not 0 connID is made the owner of
+//           vlanID, failing if it is owned by another connection, otherwise connID keeps the VLAN ID it owns or is
+//           given a free one.  first is true if no VLAN ID of the uplink was in use before.
+func (a *allocator) acquire(connID string, vlanID, minID, maxID uint32) (uint32, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	current, ok := a.ids[connID]
+	if vlanID == 0 {
+		if ok && current >= minID && current <= maxID {
+			return current, false, nil
+		}
+		for id := minID; id <= maxID; id++ {
+			if _, used := a.owners[id]; !used {
+				vlanID = id
+				break
+			}
+		}
+		if vlanID == 0 {
+			return 0, false, errors.Errorf("no free VLAN ID in range [%d, %d]", minID, maxID)
+		}
+	}
+	if vlanID < minID || vlanID > maxID {
+		return 0, false, errors.Errorf("VLAN ID %d is out of range [%d, %d]", vlanID, minID, maxID)
+	}
+	if owner, used := a.owners[vlanID]; used && owner != connID {
+		return 0, false, errors.Errorf("VLAN ID %d is already in use by connection %q", vlanID, owner)
+	}
+	if ok {
+		delete(a.owners, current)
+	}
+	first := len(a.ids) == 0
+	a.owners[vlanID] = connID
+	a.ids[connID] = vlanID
+	return vlanID, first, nil
+}
+
+// owns - returns true if connID owns a VLAN ID
+func (a *allocator) owns(connID string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, ok := a.ids[connID]
+	return ok
+}
+
+// release - releases the VLAN ID owned by connID, returns true if it was the last VLAN ID in use on the uplink, by any
+//           of its clients and servers
+func (a *allocator) release(connID string) (last bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	vlanID, ok := a.ids[connID]
+	if !ok {
+		return false
+	}
+	delete(a.owners, vlanID)
+	delete(a.ids, connID)
+	return len(a.ids) == 0
+}
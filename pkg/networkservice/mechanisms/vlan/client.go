@@ -0,0 +1,120 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vlan provides networkservice chain elements that support the VLAN Mechanism: connections are handed off to
+// a physical network as 802.1Q tagged sub-interfaces of a host uplink
+package vlan
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+)
+
+const (
+	// MECHANISM string
+	MECHANISM = "VLAN"
+	// VlanID - Mechanism.Parameters key for the VLAN ID of the connection
+	VlanID = "vlan_id"
+)
+
+type vlanClient struct {
+	uplink *uplink
+	minID  uint32
+	maxID  uint32
+}
+
+// NewClient - returns a NetworkServiceClient chain elements that support the VLAN Mechanism
+//             uplinkName - name of the vpp interface to create the VLAN sub-interfaces on
+//             opts - options for the uplink, the chain elements on the same uplink share its VLAN IDs and the
+//                    first one to use WithAFPacket decides its host interface
+func NewClient(uplinkName string, opts ...Option) networkservice.NetworkServiceClient {
+	o := applyOptions(opts)
+	return &vlanClient{
+		uplink: loadUplink(uplinkName, o),
+		minID:  o.minID,
+		maxID:  o.maxID,
+	}
+}
+
+func (v *vlanClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	connID := request.GetConnection().GetId()
+	var requested uint32
+	if mechanism := request.GetConnection().GetMechanism(); mechanism.GetType() == MECHANISM {
+		// On refresh keep the VLAN ID the connection already has
+		requested, _ = vlanID(mechanism)
+	}
+	owned := v.uplink.allocator.owns(connID)
+	id, first, err := v.uplink.allocator.acquire(connID, requested, v.minID, v.maxID)
+	if err != nil {
+		return nil, err
+	}
+	preferredMechanism := &networkservice.Mechanism{
+		Cls:  cls.REMOTE,
+		Type: MECHANISM,
+		Parameters: map[string]string{
+			VlanID: strconv.FormatUint(uint64(id), 10),
+		},
+	}
+	request.MechanismPreferences = append(request.MechanismPreferences, preferredMechanism)
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err == nil {
+		err = v.acquireSelected(ctx, conn, first)
+	}
+	if err != nil {
+		if !owned {
+			v.uplink.allocator.release(connID)
+		}
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (v *vlanClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	last := v.uplink.allocator.release(conn.GetId())
+	if err := v.uplink.appendInterfaceConfig(ctx, conn, last); err != nil {
+		return nil, err
+	}
+	return rv, nil
+}
+
+// acquireSelected - makes sure the VLAN ID of the selected mechanism is owned by conn and appends its config, with the
+//                   uplink if withUplink is true
+func (v *vlanClient) acquireSelected(ctx context.Context, conn *networkservice.Connection, withUplink bool) error {
+	if conn.GetMechanism().GetType() != MECHANISM {
+		v.uplink.allocator.release(conn.GetId())
+		return nil
+	}
+	id, err := vlanID(conn.GetMechanism())
+	if err != nil {
+		return err
+	}
+	if _, _, err = v.uplink.allocator.acquire(conn.GetId(), id, v.minID, v.maxID); err != nil {
+		return err
+	}
+	return v.uplink.appendInterfaceConfig(ctx, conn, withUplink)
+}
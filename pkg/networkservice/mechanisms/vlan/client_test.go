@@ -0,0 +1,124 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vlan_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/checkvppagentmechanism"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/vlan"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const ClientUplink = "client-uplink"
+
+func TestVlanClient(t *testing.T) {
+	// Turn off log output
+	logrus.SetOutput(ioutil.Discard)
+	testRequest := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "ConnectionId",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.REMOTE,
+				Type: vlan.MECHANISM,
+				Parameters: map[string]string{
+					vlan.VlanID: "100",
+				},
+			},
+		},
+	}
+	testConnToClose := testRequest.GetConnection()
+	suite.Run(t, checkvppagentmechanism.NewClientSuite(
+		vlan.NewClient(ClientUplink),
+		vlan.MECHANISM,
+		func(t *testing.T, mechanism *networkservice.Mechanism) {
+			require.NotNil(t, mechanism)
+			// The VLAN ID the connection already has is kept
+			assert.Equal(t, "100", mechanism.GetParameters()[vlan.VlanID])
+		},
+		func(t *testing.T, conf *configurator.Config) { // Check the vppConfig
+			// Basic Checks
+			vppInterfaces := conf.GetVppConfig().GetInterfaces()
+			require.Greater(t, len(vppInterfaces), 0)
+			vppInterface := vppInterfaces[len(vppInterfaces)-1]
+			assert.NotNil(t, vppInterface)
+			assert.Equal(t, vppinterfaces.Interface_SUB_INTERFACE, vppInterface.GetType())
+
+			// Check sub-interface parameters
+			subInterface := vppInterface.GetSub()
+			require.NotNil(t, subInterface)
+			assert.Equal(t, ClientUplink, subInterface.GetParentName())
+			assert.Equal(t, uint32(100), subInterface.GetSubId())
+			assert.Equal(t, vppinterfaces.SubInterface_POP1, subInterface.GetTagRwOption())
+		},
+		testRequest,
+		testConnToClose,
+	))
+	t.Run("AllocatesVlanID", func(t *testing.T) {
+		clientUnderTest := chain.NewNetworkServiceClient(
+			vlan.NewClient("allocates-client-uplink", vlan.WithVlanRange(10, 11)),
+			&selectMechanismClient{},
+		)
+		for _, id := range []string{"10", "11"} {
+			req := &networkservice.NetworkServiceRequest{
+				Connection: &networkservice.Connection{
+					Id: id,
+				},
+			}
+			_, err := clientUnderTest.Request(vppagent.WithConfig(context.Background()), req)
+			require.NoError(t, err)
+			require.Len(t, req.GetMechanismPreferences(), 1)
+			assert.Equal(t, id, req.GetMechanismPreferences()[0].GetParameters()[vlan.VlanID])
+			assert.Equal(t, id, req.GetConnection().GetMechanism().GetParameters()[vlan.VlanID])
+		}
+		// The range is exhausted
+		_, err := clientUnderTest.Request(vppagent.WithConfig(context.Background()), &networkservice.NetworkServiceRequest{
+			Connection: &networkservice.Connection{
+				Id: "12",
+			},
+		})
+		assert.NotNil(t, err)
+	})
+}
+
+// selectMechanismClient selects the last of the MechanismPreferences, the way a server would
+type selectMechanismClient struct{}
+
+func (s *selectMechanismClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	request.GetConnection().Mechanism = request.GetMechanismPreferences()[len(request.GetMechanismPreferences())-1].Clone()
+	return next.Client(ctx).Request(ctx, request, opts...)
+}
+
+func (s *selectMechanismClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
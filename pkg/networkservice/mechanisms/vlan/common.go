@@ -0,0 +1,122 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vlan
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+// uplink is the vpp interface the VLAN sub-interfaces are created on
+type uplink struct {
+	name       string
+	hostIfName string
+	allocator  *allocator
+}
+
+// uplinks - the uplinks by name, shared by all the vlan chain elements, so a client and a server on the same uplink
+//           neither hand out the same VLAN ID nor remove the AF_PACKET interface the other one still uses
+var uplinks = struct {
+	byName map[string]*uplink
+	mu     sync.Mutex
+}{
+	byName: make(map[string]*uplink),
+}
+
+// loadUplink - returns the uplink named name, created as configured by opts if it is new
+func loadUplink(name string, o *option) *uplink {
+	uplinks.mu.Lock()
+	defer uplinks.mu.Unlock()
+	u, ok := uplinks.byName[name]
+	if !ok {
+		u = &uplink{
+			name:      name,
+			allocator: newAllocator(),
+		}
+		uplinks.byName[name] = u
+	}
+	if u.hostIfName == "" {
+		u.hostIfName = o.hostIfName
+	}
+	return u
+}
+
+// vlanID - returns the VLAN ID requested by the mechanism, 0 if it requests none
+func vlanID(mechanism *networkservice.Mechanism) (uint32, error) {
+	value, ok := mechanism.GetParameters()[VlanID]
+	if !ok || value == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || id < minVlanID || id > maxVlanID {
+		return 0, errors.Errorf("vlan mechanism parameter %s has wrong value: %q", VlanID, value)
+	}
+	return uint32(id), nil
+}
+
+// appendInterfaceConfig - appends the sub-interface with the VLAN ID owned by conn, and the uplink itself when
+//                         withUplink is true
+func (u *uplink) appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection, withUplink bool) error {
+	if conn.GetMechanism().GetType() != MECHANISM {
+		return nil
+	}
+	id, err := vlanID(conn.GetMechanism())
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		return errors.Errorf("vlan mechanism parameter %s is not set", VlanID)
+	}
+	conf := vppagent.Config(ctx)
+	if withUplink && u.hostIfName != "" {
+		// Note: the uplink is prepended, so the sub-interface stays the last interface for l2xconnect to pick up
+		conf.GetVppConfig().Interfaces = append([]*vpp.Interface{{
+			Name:    u.name,
+			Type:    vppinterfaces.Interface_AF_PACKET,
+			Enabled: true,
+			Link: &vppinterfaces.Interface_Afpacket{
+				Afpacket: &vppinterfaces.AfpacketLink{
+					HostIfName: u.hostIfName,
+				},
+			},
+		}}, conf.GetVppConfig().Interfaces...)
+	}
+	conf.GetVppConfig().Interfaces = append(conf.GetVppConfig().Interfaces, &vpp.Interface{
+		Name:    conn.GetId(),
+		Type:    vppinterfaces.Interface_SUB_INTERFACE,
+		Enabled: true,
+		Link: &vppinterfaces.Interface_Sub{
+			Sub: &vppinterfaces.SubInterface{
+				ParentName: u.name,
+				SubId:      id,
+				// Pop the tag on the way in (and push it back on the way out), so the payload cross connected
+				// to the other side is untagged
+				TagRwOption: vppinterfaces.SubInterface_POP1,
+			},
+		},
+	})
+	return nil
+}
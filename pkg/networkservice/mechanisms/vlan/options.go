@@ -0,0 +1,54 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vlan
+
+type option struct {
+	minID      uint32
+	maxID      uint32
+	hostIfName string
+}
+
+// Option - option for use with vlan.NewClient(...) and vlan.NewServer(...)
+type Option func(opt *option)
+
+// WithVlanRange - sets the range [minID, maxID] VLAN IDs are allocated from, the default is [1, 4094]
+func WithVlanRange(minID, maxID uint32) Option {
+	return func(opt *option) {
+		opt.minID = minID
+		opt.maxID = maxID
+	}
+}
+
+// WithAFPacket - creates the uplink as an AF_PACKET interface on the host interface hostIfName, rather than
+//                using an existing vpp interface.  The AF_PACKET interface is removed when its last connection is
+//                closed.
+func WithAFPacket(hostIfName string) Option {
+	return func(opt *option) {
+		opt.hostIfName = hostIfName
+	}
+}
+
+func applyOptions(opts []Option) *option {
+	o := &option{
+		minID: minVlanID,
+		maxID: maxVlanID,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
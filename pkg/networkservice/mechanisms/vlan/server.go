@@ -0,0 +1,88 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vlan
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+)
+
+type vlanServer struct {
+	uplink *uplink
+	minID  uint32
+	maxID  uint32
+}
+
+// NewServer - return a NetworkServiceServer chain elements that support the VLAN Mechanism
+//             uplinkName - name of the vpp interface to create the VLAN sub-interfaces on
+//             opts - options for the uplink, the chain elements on the same uplink share its VLAN IDs and the
+//                    first one to use WithAFPacket decides its host interface
+func NewServer(uplinkName string, opts ...Option) networkservice.NetworkServiceServer {
+	o := applyOptions(opts)
+	return &vlanServer{
+		uplink: loadUplink(uplinkName, o),
+		minID:  o.minID,
+		maxID:  o.maxID,
+	}
+}
+
+func (v *vlanServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	mechanism := request.GetConnection().GetMechanism()
+	if mechanism.GetType() != MECHANISM {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	connID := request.GetConnection().GetId()
+	requested, err := vlanID(mechanism)
+	if err != nil {
+		return nil, err
+	}
+	owned := v.uplink.allocator.owns(connID)
+	id, first, err := v.uplink.allocator.acquire(connID, requested, v.minID, v.maxID)
+	if err != nil {
+		return nil, err
+	}
+	if mechanism.GetParameters() == nil {
+		mechanism.Parameters = make(map[string]string)
+	}
+	mechanism.GetParameters()[VlanID] = strconv.FormatUint(uint64(id), 10)
+	conn, err := v.request(ctx, request, first)
+	if err != nil && !owned {
+		v.uplink.allocator.release(connID)
+	}
+	return conn, err
+}
+
+func (v *vlanServer) request(ctx context.Context, request *networkservice.NetworkServiceRequest, withUplink bool) (*networkservice.Connection, error) {
+	if err := v.uplink.appendInterfaceConfig(ctx, request.GetConnection(), withUplink); err != nil {
+		return nil, err
+	}
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (v *vlanServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	last := v.uplink.allocator.release(conn.GetId())
+	if err := v.uplink.appendInterfaceConfig(ctx, conn, last); err != nil {
+		return nil, err
+	}
+	return next.Server(ctx).Close(ctx, conn)
+}
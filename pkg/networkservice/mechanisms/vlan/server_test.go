@@ -0,0 +1,177 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vlan_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/checkvppagentmechanism"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/vlan"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const (
+	Uplink         = "uplink"
+	AFPacketUplink = "afpacket-uplink"
+	SharedUplink   = "shared-uplink"
+	HostIfName     = "eth1"
+	InvalidVlanID  = "Invalid"
+)
+
+func TestVlanServer(t *testing.T) {
+	// Turn off log output
+	logrus.SetOutput(ioutil.Discard)
+	testRequest := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "ConnectionId",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.REMOTE,
+				Type: vlan.MECHANISM,
+				Parameters: map[string]string{
+					vlan.VlanID: "100",
+				},
+			},
+		},
+	}
+	suite.Run(t, checkvppagentmechanism.NewServerSuite(
+		vlan.NewServer(Uplink),
+		vlan.MECHANISM,
+		func(t *testing.T, mechanism *networkservice.Mechanism) {
+			assert.Equal(t, "100", mechanism.GetParameters()[vlan.VlanID])
+		},
+		func(t *testing.T, conf *configurator.Config) {
+			// Basic Checks
+			vppInterfaces := conf.GetVppConfig().GetInterfaces()
+			require.Greater(t, len(vppInterfaces), 0)
+			vppInterface := vppInterfaces[len(vppInterfaces)-1]
+			assert.NotNil(t, vppInterface)
+			// Check sub-interface parameters
+			subInterface := vppInterface.GetSub()
+			require.NotNil(t, subInterface)
+			assert.Equal(t, Uplink, subInterface.GetParentName())
+			assert.Equal(t, uint32(100), subInterface.GetSubId())
+		},
+		testRequest,
+		testRequest.GetConnection(),
+	))
+	t.Run("InvalidVlanID", func(t *testing.T) {
+		req := testRequest.Clone()
+		req.GetConnection().GetMechanism().GetParameters()[vlan.VlanID] = InvalidVlanID
+		serverUnderTest := vlan.NewServer(Uplink)
+		conn, err := serverUnderTest.Request(vppagent.WithConfig(context.Background()), req)
+		assert.Nil(t, conn)
+		assert.NotNil(t, err)
+		_, err = serverUnderTest.Close(vppagent.WithConfig(context.Background()), req.GetConnection())
+		assert.NotNil(t, err)
+	})
+	t.Run("AllocatesVlanID", func(t *testing.T) {
+		req := testRequest.Clone()
+		delete(req.GetConnection().GetMechanism().GetParameters(), vlan.VlanID)
+		conn, err := vlan.NewServer("allocates-uplink", vlan.WithVlanRange(200, 300)).Request(vppagent.WithConfig(context.Background()), req)
+		require.NoError(t, err)
+		assert.Equal(t, "200", conn.GetMechanism().GetParameters()[vlan.VlanID])
+	})
+	t.Run("VlanIDInUse", func(t *testing.T) {
+		serverUnderTest := vlan.NewServer("in-use-uplink")
+		_, err := serverUnderTest.Request(vppagent.WithConfig(context.Background()), testRequest.Clone())
+		require.NoError(t, err)
+		req := testRequest.Clone()
+		req.GetConnection().Id = "OtherConnectionId"
+		_, err = serverUnderTest.Request(vppagent.WithConfig(context.Background()), req)
+		assert.NotNil(t, err)
+		_, err = serverUnderTest.Close(vppagent.WithConfig(context.Background()), testRequest.Clone().GetConnection())
+		require.NoError(t, err)
+		_, err = serverUnderTest.Request(vppagent.WithConfig(context.Background()), req)
+		assert.NoError(t, err)
+	})
+	t.Run("WithAFPacket", func(t *testing.T) {
+		serverUnderTest := vlan.NewServer(AFPacketUplink, vlan.WithAFPacket(HostIfName))
+		ctx := vppagent.WithConfig(context.Background())
+		_, err := serverUnderTest.Request(ctx, testRequest.Clone())
+		require.NoError(t, err)
+		vppInterfaces := vppagent.Config(ctx).GetVppConfig().GetInterfaces()
+		require.Len(t, vppInterfaces, 2)
+		assert.Equal(t, AFPacketUplink, vppInterfaces[0].GetName())
+		assert.Equal(t, HostIfName, vppInterfaces[0].GetAfpacket().GetHostIfName())
+		assert.Equal(t, vppinterfaces.Interface_SUB_INTERFACE, vppInterfaces[1].GetType())
+
+		// The uplink is only added together with its first connection, not on refresh
+		ctx = vppagent.WithConfig(context.Background())
+		_, err = serverUnderTest.Request(ctx, testRequest.Clone())
+		require.NoError(t, err)
+		assert.Len(t, vppagent.Config(ctx).GetVppConfig().GetInterfaces(), 1)
+
+		other := testRequest.Clone()
+		other.GetConnection().Id = "OtherConnectionId"
+		other.GetConnection().GetMechanism().GetParameters()[vlan.VlanID] = "101"
+		_, err = serverUnderTest.Request(vppagent.WithConfig(context.Background()), other)
+		require.NoError(t, err)
+
+		// The uplink is only removed together with its last connection
+		ctx = vppagent.WithConfig(context.Background())
+		_, err = serverUnderTest.Close(ctx, testRequest.Clone().GetConnection())
+		require.NoError(t, err)
+		assert.Len(t, vppagent.Config(ctx).GetVppConfig().GetInterfaces(), 1)
+		ctx = vppagent.WithConfig(context.Background())
+		_, err = serverUnderTest.Close(ctx, other.GetConnection())
+		require.NoError(t, err)
+		assert.Len(t, vppagent.Config(ctx).GetVppConfig().GetInterfaces(), 2)
+	})
+	t.Run("SharedUplink", func(t *testing.T) {
+		// A client and a server on the same uplink share its VLAN IDs...
+		serverUnderTest := vlan.NewServer(SharedUplink, vlan.WithVlanRange(10, 11), vlan.WithAFPacket(HostIfName))
+		clientUnderTest := chain.NewNetworkServiceClient(
+			vlan.NewClient(SharedUplink, vlan.WithVlanRange(10, 11), vlan.WithAFPacket(HostIfName)),
+			&selectMechanismClient{},
+		)
+		serverReq := testRequest.Clone()
+		delete(serverReq.GetConnection().GetMechanism().GetParameters(), vlan.VlanID)
+		serverConn, err := serverUnderTest.Request(vppagent.WithConfig(context.Background()), serverReq)
+		require.NoError(t, err)
+		assert.Equal(t, "10", serverConn.GetMechanism().GetParameters()[vlan.VlanID])
+		clientConn, err := clientUnderTest.Request(vppagent.WithConfig(context.Background()), &networkservice.NetworkServiceRequest{
+			Connection: &networkservice.Connection{
+				Id: "ClientConnectionId",
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "11", clientConn.GetMechanism().GetParameters()[vlan.VlanID])
+
+		// ... and the uplink, which stays as long as any of them uses it
+		ctx := vppagent.WithConfig(context.Background())
+		_, err = serverUnderTest.Close(ctx, serverConn)
+		require.NoError(t, err)
+		assert.Len(t, vppagent.Config(ctx).GetVppConfig().GetInterfaces(), 1)
+		ctx = vppagent.WithConfig(context.Background())
+		_, err = clientUnderTest.Close(ctx, clientConn)
+		require.NoError(t, err)
+		assert.Len(t, vppagent.Config(ctx).GetVppConfig().GetInterfaces(), 2)
+	})
+}
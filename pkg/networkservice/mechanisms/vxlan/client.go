@@ -36,6 +36,7 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/vxlan"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/tunnelprotection"
 )
 
 const (
@@ -48,6 +49,7 @@ func EmptyInitFunc(conf *configurator.Config) error { return nil }
 
 type vxlanClient struct {
 	srcIP    net.IP
+	ipsec    bool
 	initOnce sync.Once
 	initFunc func(conf *configurator.Config) error
 	err      error
@@ -56,12 +58,18 @@ type vxlanClient struct {
 // NewClient - returns a NetworkServiceClient chain elements that support the vxlan Mechanism
 //             srcIp - srcIP to use for vxlan tunnels
 //             initFunc - function to do any one time config so that vxlan tunnels can work
-func NewClient(srcIP net.IP, initFunc func(conf *configurator.Config) error) networkservice.NetworkServiceClient {
+//             opts - options for the vxlan tunnels
+func NewClient(srcIP net.IP, initFunc func(conf *configurator.Config) error, opts ...Option) networkservice.NetworkServiceClient {
 	if initFunc == nil {
 		initFunc = EmptyInitFunc
 	}
+	o := &option{}
+	for _, opt := range opts {
+		opt(o)
+	}
 	return &vxlanClient{
 		srcIP:    srcIP,
+		ipsec:    o.ipsec,
 		initFunc: initFunc,
 		err:      errors.New("vxlanClient: vppagent uninitialized"),
 	}
@@ -75,18 +83,35 @@ func (v *vxlanClient) Request(ctx context.Context, request *networkservice.Netwo
 			vxlan.SrcIP: v.srcIP.String(),
 		},
 	}
+	if v.ipsec {
+		var previous map[string]string
+		if vxlan.ToMechanism(request.GetConnection().GetMechanism()) != nil {
+			previous = request.GetConnection().GetMechanism().GetParameters()
+		}
+		if err := tunnelprotection.Generate(preferredMechanism.GetParameters(), previous); err != nil {
+			return nil, err
+		}
+	}
 	request.MechanismPreferences = append(request.MechanismPreferences, preferredMechanism)
 	rv, err := next.Client(ctx).Request(ctx, request, opts...)
 	if err != nil {
 		return nil, err
 	}
+	// Never fall back to a cleartext tunnel if the server dropped the SAs
+	if v.ipsec && vxlan.ToMechanism(rv.GetMechanism()) != nil && !tunnelprotection.Enabled(rv.GetMechanism().GetParameters()) {
+		return nil, errors.New(ipsecIsRequired)
+	}
 	v.initOnce.Do(func() {
 		v.err = v.initFunc(vppagent.Config(ctx))
 	})
 	if v.err != nil {
 		return nil, v.err
 	}
-	if configErr := v.appendInterfaceConfig(ctx, request.GetConnection()); configErr != nil {
+	refresh := tunnelprotection.Acquired(request.GetConnection().GetId())
+	if configErr := v.appendInterfaceConfig(ctx, request.GetConnection(), false); configErr != nil {
+		if !refresh {
+			tunnelprotection.Release(request.GetConnection().GetId())
+		}
 		return nil, configErr
 	}
 	return rv, err
@@ -103,13 +128,15 @@ func (v *vxlanClient) Close(ctx context.Context, conn *networkservice.Connection
 	if v.err != nil {
 		return nil, v.err
 	}
-	if configErr := v.appendInterfaceConfig(ctx, conn); configErr != nil {
+	configErr := v.appendInterfaceConfig(ctx, conn, true)
+	tunnelprotection.Release(conn.GetId())
+	if configErr != nil {
 		return nil, configErr
 	}
 	return rv, err
 }
 
-func (v *vxlanClient) appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection) error {
+func (v *vxlanClient) appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection, isClose bool) error {
 	conf := vppagent.Config(ctx)
 	if mechanism := vxlan.ToMechanism(conn.GetMechanism()); mechanism != nil {
 		vni := mechanism.VNI()
@@ -128,6 +155,9 @@ func (v *vxlanClient) appendInterfaceConfig(ctx context.Context, conn *networkse
 				},
 			},
 		})
+		if tunnelprotection.Enabled(conn.GetMechanism().GetParameters()) {
+			return tunnelprotection.AppendConfig(conf, conn.GetId(), conn.GetMechanism().GetParameters(), true, isClose)
+		}
 	}
 	return nil
 }
@@ -20,6 +20,7 @@ import (
 	"context"
 	"io/ioutil"
 	"net"
+	"strconv"
 	"testing"
 
 	"github.com/sirupsen/logrus"
@@ -35,6 +36,7 @@ import (
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/checkvppagentmechanism"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/vxlan"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/tunnelprotection"
 )
 
 func TestVxlanClient(t *testing.T) {
@@ -95,4 +97,39 @@ func TestVxlanClient(t *testing.T) {
 		_, err = clientUnderTest.Close(vppagent.WithConfig(context.Background()), req.GetConnection())
 		assert.NotNil(t, err)
 	})
+	t.Run("WithIPSec", func(t *testing.T) {
+		req := testRequest.Clone()
+		require.NoError(t, tunnelprotection.Generate(req.GetConnection().GetMechanism().GetParameters(), nil))
+		clientUnderTest := vxlan.NewClient(srcIP, vxlan.EmptyInitFunc, vxlan.WithIPSec())
+		ctx := vppagent.WithConfig(context.Background())
+		conn, err := clientUnderTest.Request(ctx, req)
+		require.NoError(t, err)
+		require.NotNil(t, conn)
+
+		// The offered mechanism must carry the SAs of the connection
+		preferredParameters := req.GetMechanismPreferences()[len(req.GetMechanismPreferences())-1].GetParameters()
+		for _, key := range []string{tunnelprotection.SrcSPI, tunnelprotection.SrcCryptoKey, tunnelprotection.DstSPI, tunnelprotection.DstCryptoKey} {
+			assert.Equal(t, req.GetConnection().GetMechanism().GetParameters()[key], preferredParameters[key])
+		}
+
+		vppConfig := vppagent.Config(ctx).GetVppConfig()
+		require.Len(t, vppConfig.GetIpsecSas(), 2)
+		require.Len(t, vppConfig.GetIpsecTunnelProtections(), 1)
+		outSA, inSA := vppConfig.GetIpsecSas()[0], vppConfig.GetIpsecSas()[1]
+		assert.Equal(t, preferredParameters[tunnelprotection.SrcSPI], strconv.FormatUint(uint64(outSA.GetSpi()), 10))
+		assert.Equal(t, preferredParameters[tunnelprotection.DstSPI], strconv.FormatUint(uint64(inSA.GetSpi()), 10))
+		protection := vppConfig.GetIpsecTunnelProtections()[0]
+		assert.Equal(t, conn.GetId(), protection.GetInterface())
+		assert.Equal(t, []uint32{outSA.GetIndex()}, protection.GetSaOut())
+		assert.Equal(t, []uint32{inSA.GetIndex()}, protection.GetSaIn())
+	})
+	t.Run("IPSecRequired", func(t *testing.T) {
+		// The returned mechanism carries no SAs
+		clientUnderTest := vxlan.NewClient(srcIP, vxlan.EmptyInitFunc, vxlan.WithIPSec())
+		ctx := vppagent.WithConfig(context.Background())
+		conn, err := clientUnderTest.Request(ctx, testRequest.Clone())
+		assert.Nil(t, conn)
+		assert.NotNil(t, err)
+		assert.Empty(t, vppagent.Config(ctx).GetVppConfig().GetInterfaces())
+	})
 }
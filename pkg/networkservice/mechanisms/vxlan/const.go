@@ -16,4 +16,7 @@
 
 package vxlan
 
-const vniHasWrongValue = "vni is not set or has wrong value"
+const (
+	vniHasWrongValue = "vni is not set or has wrong value"
+	ipsecIsRequired  = "ipsec is required but the mechanism carries no security associations"
+)
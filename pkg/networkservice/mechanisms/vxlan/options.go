@@ -0,0 +1,33 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vxlan
+
+type option struct {
+	ipsec bool
+}
+
+// Option - option for use with vxlan.NewClient(...) and vxlan.NewServer(...)
+type Option func(opt *option)
+
+// WithIPSec - protects the vxlan tunnels with IPsec tunnel protection.
+//             The client generates the SAs for each connection and offers them in the Mechanism.Parameters, the
+//             server refuses connections that don't carry them.
+func WithIPSec() Option {
+	return func(opt *option) {
+		opt.ipsec = true
+	}
+}
@@ -33,10 +33,12 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/tunnelprotection"
 )
 
 type vxlanServer struct {
 	dstIP    net.IP
+	ipsec    bool
 	initOnce sync.Once
 	initFunc func(conf *configurator.Config) error
 	err      error
@@ -45,12 +47,18 @@ type vxlanServer struct {
 // NewServer - return a NetworkServiceServer chain elements that support the vxlan Mechanism
 //             dstIP - dstIP to use for vxlan tunnels
 //             initFunc - function to do any one time config so that vxlan tunnels can work
-func NewServer(dstIP net.IP, initFunc func(conf *configurator.Config) error) networkservice.NetworkServiceServer {
+//             opts - options for the vxlan tunnels
+func NewServer(dstIP net.IP, initFunc func(conf *configurator.Config) error, opts ...Option) networkservice.NetworkServiceServer {
 	if initFunc == nil {
 		initFunc = EmptyInitFunc
 	}
+	o := &option{}
+	for _, opt := range opts {
+		opt(o)
+	}
 	return &vxlanServer{
 		dstIP:    dstIP,
+		ipsec:    o.ipsec,
 		initFunc: initFunc,
 		err:      errors.New("vxlanClient: vppagent uninitialized"),
 	}
@@ -63,10 +71,15 @@ func (v *vxlanServer) Request(ctx context.Context, request *networkservice.Netwo
 	if v.err != nil {
 		return nil, v.err
 	}
-	if err := v.appendInterfaceConfig(ctx, request.GetConnection()); err != nil {
+	refresh := tunnelprotection.Acquired(request.GetConnection().GetId())
+	if err := v.appendInterfaceConfig(ctx, request.GetConnection(), false); err != nil {
 		return nil, err
 	}
-	return next.Server(ctx).Request(ctx, request)
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil && !refresh {
+		tunnelprotection.Release(request.GetConnection().GetId())
+	}
+	return conn, err
 }
 
 func (v *vxlanServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
@@ -76,13 +89,18 @@ func (v *vxlanServer) Close(ctx context.Context, conn *networkservice.Connection
 	if v.err != nil {
 		return nil, v.err
 	}
-	if err := v.appendInterfaceConfig(ctx, conn); err != nil {
+	if err := v.appendInterfaceConfig(ctx, conn, true); err != nil {
+		return nil, err
+	}
+	rv, err := next.Server(ctx).Close(ctx, conn)
+	if err != nil {
 		return nil, err
 	}
-	return next.Server(ctx).Close(ctx, conn)
+	tunnelprotection.Release(conn.GetId())
+	return rv, nil
 }
 
-func (v *vxlanServer) appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection) error {
+func (v *vxlanServer) appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection, isClose bool) error {
 	conf := vppagent.Config(ctx)
 	if mechanism := vxlan.ToMechanism(conn.GetMechanism()); mechanism != nil {
 		conn.GetMechanism().GetParameters()[vxlan.DstIP] = v.dstIP.String()
@@ -91,6 +109,9 @@ func (v *vxlanServer) appendInterfaceConfig(ctx context.Context, conn *networkse
 		if vni == 0 {
 			return errors.New(vniHasWrongValue)
 		}
+		if v.ipsec && !tunnelprotection.Enabled(conn.GetMechanism().GetParameters()) {
+			return errors.New(ipsecIsRequired)
+		}
 		conf.GetVppConfig().Interfaces = append(conf.GetVppConfig().Interfaces, &vpp.Interface{
 			Name:    conn.GetId(),
 			Type:    vppinterfaces.Interface_VXLAN_TUNNEL,
@@ -104,6 +125,9 @@ func (v *vxlanServer) appendInterfaceConfig(ctx context.Context, conn *networkse
 				},
 			},
 		})
+		if tunnelprotection.Enabled(conn.GetMechanism().GetParameters()) {
+			return tunnelprotection.AppendConfig(conf, conn.GetId(), conn.GetMechanism().GetParameters(), false, isClose)
+		}
 	}
 	return nil
 }
@@ -20,6 +20,7 @@ import (
 	"context"
 	"io/ioutil"
 	"net"
+	"strconv"
 	"testing"
 
 	"github.com/sirupsen/logrus"
@@ -35,6 +36,7 @@ import (
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/checkvppagentmechanism"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/vxlan"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/tunnelprotection"
 )
 
 const (
@@ -96,4 +98,51 @@ func TestVxlanServer(t *testing.T) {
 		_, err = serverUnderTest.Close(vppagent.WithConfig(context.Background()), req.GetConnection())
 		assert.NotNil(t, err)
 	})
+	t.Run("WithIPSec", func(t *testing.T) {
+		req := testRequest.Clone()
+		parameters := req.GetConnection().GetMechanism().GetParameters()
+		require.NoError(t, tunnelprotection.Generate(parameters, nil))
+		serverUnderTest := vxlan.NewServer(dstIP, vxlan.EmptyInitFunc, vxlan.WithIPSec())
+		ctx := vppagent.WithConfig(context.Background())
+		conn, err := serverUnderTest.Request(ctx, req)
+		require.NoError(t, err)
+		require.NotNil(t, conn)
+
+		vppConfig := vppagent.Config(ctx).GetVppConfig()
+		require.Len(t, vppConfig.GetIpsecSas(), 2)
+		require.Len(t, vppConfig.GetIpsecTunnelProtections(), 1)
+		// Note: the server sends using the Dst SA and receives using the Src SA
+		outSA, inSA := vppConfig.GetIpsecSas()[0], vppConfig.GetIpsecSas()[1]
+		assert.Equal(t, parameters[tunnelprotection.DstSPI], strconv.FormatUint(uint64(outSA.GetSpi()), 10))
+		assert.Equal(t, parameters[tunnelprotection.DstCryptoKey], outSA.GetCryptoKey())
+		assert.Equal(t, parameters[tunnelprotection.SrcSPI], strconv.FormatUint(uint64(inSA.GetSpi()), 10))
+		assert.Equal(t, parameters[tunnelprotection.SrcCryptoKey], inSA.GetCryptoKey())
+		protection := vppConfig.GetIpsecTunnelProtections()[0]
+		assert.Equal(t, conn.GetId(), protection.GetInterface())
+		assert.Equal(t, []uint32{outSA.GetIndex()}, protection.GetSaOut())
+		assert.Equal(t, []uint32{inSA.GetIndex()}, protection.GetSaIn())
+
+		// The SAs are removed together with the tunnel on Close
+		closeCtx := vppagent.WithConfig(context.Background())
+		_, err = serverUnderTest.Close(closeCtx, conn)
+		require.NoError(t, err)
+		assert.Len(t, vppagent.Config(closeCtx).GetVppConfig().GetIpsecSas(), 2)
+		assert.Len(t, vppagent.Config(closeCtx).GetVppConfig().GetIpsecTunnelProtections(), 1)
+
+		// Without the SA indexes, as after a restart, no SAs are allocated just to be deleted
+		closeCtx = vppagent.WithConfig(context.Background())
+		_, err = serverUnderTest.Close(closeCtx, conn)
+		require.NoError(t, err)
+		assert.Len(t, vppagent.Config(closeCtx).GetVppConfig().GetInterfaces(), 1)
+		assert.Empty(t, vppagent.Config(closeCtx).GetVppConfig().GetIpsecSas())
+		assert.Empty(t, vppagent.Config(closeCtx).GetVppConfig().GetIpsecTunnelProtections())
+		assert.False(t, tunnelprotection.Acquired(conn.GetId()))
+	})
+	t.Run("IPSecRequired", func(t *testing.T) {
+		req := testRequest.Clone()
+		serverUnderTest := vxlan.NewServer(dstIP, vxlan.EmptyInitFunc, vxlan.WithIPSec())
+		conn, err := serverUnderTest.Request(vppagent.WithConfig(context.Background()), req)
+		assert.Nil(t, conn)
+		assert.NotNil(t, err)
+	})
 }
@@ -29,14 +29,16 @@ import (
 type contextKeyType string
 
 const (
-	configKey contextKeyType = "configKey"
+	configKey      contextKeyType = "configKey"
+	staleConfigKey contextKeyType = "staleConfigKey"
 )
 
-// WithConfig returns a context that contains a vppagent config
+// WithConfig returns a context that contains a vppagent config, along with an empty stale config
 func WithConfig(ctx context.Context) context.Context {
 	if config, ok := ctx.Value(configKey).(*configurator.Config); ok && config != nil {
 		return ctx
 	}
+	ctx = context.WithValue(ctx, staleConfigKey, &configurator.Config{})
 	rv := &configurator.Config{
 		VppConfig:      &vpp.ConfigData{},
 		LinuxConfig:    &linux.ConfigData{},
@@ -52,3 +54,13 @@ func Config(ctx context.Context) *configurator.Config {
 	}
 	return nil
 }
+
+// StaleConfig - returns the vppagent *configurator.Config stored in ctx of what a refresh leaves behind.  It is deleted
+//               by the commit chain elements once Config(ctx) is committed.  Its vpp, linux and netalloc configs are
+//               nil until set by the chain elements adding to it.
+func StaleConfig(ctx context.Context) *configurator.Config {
+	if rv, ok := ctx.Value(staleConfigKey).(*configurator.Config); ok {
+		return rv
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vrf
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+type vrfClient struct {
+	tables *Tables
+}
+
+// NewClient creates a NetworkServiceClient chain element binding the vpp interface of the connection to the vrf of its
+// tenant or network service, so connections of different vrfs may use overlapping prefixes.
+// It must precede the mechanism and follow the connectioncontext chain elements, so the ip addresses, arps and routes
+// land in the vrf.  Connections without the vrf label get the vrf of their network service, see NewTables.
+func NewClient(opts ...Option) networkservice.NetworkServiceClient {
+	o := applyOptions(opts)
+	return &vrfClient{
+		tables: o.tables,
+	}
+}
+
+func (v *vrfClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	id, staleID, _, ok := v.tables.load(conn)
+	if ok {
+		bind(vppagent.Config(ctx), id, true)
+	}
+	if staleID != 0 {
+		// The connection moved out of a vrf no other connection uses
+		appendTables(vppagent.StaleConfig(ctx), staleID)
+	}
+	return conn, nil
+}
+
+func (v *vrfClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// Only the last connection of the vrf deletes its tables
+	if id, last, _, ok := v.tables.unload(conn); ok {
+		bind(vppagent.Config(ctx), id, last)
+	}
+	return rv, nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vrf_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/inject/injecterror"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vrf"
+)
+
+// interfaceClient - appends the vpp interface of the connection, as the mechanism clients do
+type interfaceClient struct{}
+
+func (c *interfaceClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.appendInterface(ctx, conn)
+	return conn, nil
+}
+
+func (c *interfaceClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.appendInterface(ctx, conn)
+	return rv, nil
+}
+
+func (c *interfaceClient) appendInterface(ctx context.Context, conn *networkservice.Connection) {
+	vppConfig := vppagent.Config(ctx).GetVppConfig()
+	vppConfig.Interfaces = append(vppConfig.Interfaces, &vpp.Interface{Name: "client-" + conn.GetId()})
+}
+
+func TestVrfClient(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	tables := vrf.NewTables(vrf.LabelKey)
+	client := chain.NewNetworkServiceClient(
+		vrf.NewClient(vrf.WithTables(tables)),
+		&interfaceClient{},
+	)
+	failing := chain.NewNetworkServiceClient(
+		vrf.NewClient(vrf.WithTables(tables)),
+		&interfaceClient{},
+		injecterror.NewClient(),
+	)
+
+	// A failed request leaves no vrf behind
+	_, err := failing.Request(vppagent.WithConfig(context.Background()), request("id1", "tenant1"))
+	require.Error(t, err)
+	ctx := vppagent.WithConfig(context.Background())
+	conn1, err := client.Request(ctx, request("id2", "tenant2"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetVrf())
+	assert.Equal(t, tables(1), vppagent.Config(ctx).GetVppConfig().GetVrfs())
+
+	ctx = vppagent.WithConfig(context.Background())
+	conn2, err := client.Request(ctx, request("id3", "tenant2"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetVrf())
+
+	// A failed close leaves the connection in its vrf
+	_, err = failing.Close(vppagent.WithConfig(context.Background()), conn1)
+	require.Error(t, err)
+
+	// The tables are only deleted with the last connection of the vrf
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = client.Close(ctx, conn1)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetVrf())
+	assert.Empty(t, vppagent.Config(ctx).GetVppConfig().GetVrfs())
+
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = client.Close(ctx, conn2)
+	require.NoError(t, err)
+	assert.Equal(t, tables(1), vppagent.Config(ctx).GetVppConfig().GetVrfs())
+}
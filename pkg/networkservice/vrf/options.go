@@ -0,0 +1,50 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vrf
+
+// LabelKey - default key of the connection label naming the vrf of the connection
+const LabelKey = "vrf"
+
+// defaultTables - the Tables of the chain elements given none, shared so their connections are isolated from one
+//                 another as well
+var defaultTables = NewTables(LabelKey)
+
+type option struct {
+	tables *Tables
+}
+
+// Option - option for use with vrf.NewServer(...) and vrf.NewClient(...)
+type Option func(opt *option)
+
+// WithTables - sets the Tables allocating the vrfs, so they are shared between chain elements.
+//              Default: NewTables(LabelKey) shared by all the chain elements without WithTables
+func WithTables(tables *Tables) Option {
+	return func(opt *option) {
+		opt.tables = tables
+	}
+}
+
+func applyOptions(opts []Option) *option {
+	o := &option{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.tables == nil {
+		o.tables = defaultTables
+	}
+	return o
+}
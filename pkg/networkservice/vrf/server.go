@@ -0,0 +1,80 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vrf provides networkservice chain elements isolating the vpp interfaces of connections in per tenant or
+// per network service vrfs
+package vrf
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+type vrfServer struct {
+	tables *Tables
+}
+
+// NewServer creates a NetworkServiceServer chain element binding the vpp interface of the connection to the vrf of its
+// tenant or network service, so connections of different vrfs may use overlapping prefixes.
+// It must follow the mechanism and precede the connectioncontext chain elements, so the ip addresses, arps and routes
+// land in the vrf.  Connections without the vrf label get the vrf of their network service, see NewTables.
+func NewServer(opts ...Option) networkservice.NetworkServiceServer {
+	o := applyOptions(opts)
+	return &vrfServer{
+		tables: o.tables,
+	}
+}
+
+func (v *vrfServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	id, staleID, undo, ok := v.tables.load(request.GetConnection())
+	if ok {
+		bind(vppagent.Config(ctx), id, true)
+	}
+	if staleID != 0 {
+		// The connection moved out of a vrf no other connection uses
+		appendTables(vppagent.StaleConfig(ctx), staleID)
+	}
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil {
+		if ok {
+			undo()
+		}
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (v *vrfServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	// Only the last connection of the vrf deletes its tables
+	id, last, undo, ok := v.tables.unload(conn)
+	if ok {
+		bind(vppagent.Config(ctx), id, last)
+	}
+	rv, err := next.Server(ctx).Close(ctx, conn)
+	if err != nil {
+		if ok {
+			undo()
+		}
+		return nil, err
+	}
+	return rv, nil
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vrf_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	memif_mechanisms "github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/inject/injecterror"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vpp_l3 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l3"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ipcontext/routes"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vrf"
+)
+
+func request(id, tenant string) *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id:             id,
+			NetworkService: "ns",
+			Labels:         map[string]string{vrf.LabelKey: tenant},
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: memif_mechanisms.MECHANISM,
+			},
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					SrcIpAddr: "172.16.1.1/32",
+					DstIpAddr: "172.16.1.0/32",
+				},
+			},
+		},
+	}
+}
+
+func tables(id uint32) []*vpp_l3.VrfTable {
+	return []*vpp_l3.VrfTable{
+		{Id: id, Protocol: vpp_l3.VrfTable_IPV4},
+		{Id: id, Protocol: vpp_l3.VrfTable_IPV6},
+	}
+}
+
+func TestVrfServer(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(t.TempDir()),
+		vrf.NewServer(vrf.WithTables(vrf.NewTables(vrf.LabelKey))),
+		routes.NewServer(),
+	)
+
+	// Connections of different tenants with overlapping prefixes get different vrfs
+	ctx1 := vppagent.WithConfig(context.Background())
+	conn1, err := server.Request(ctx1, request("id1", "tenant1"))
+	require.NoError(t, err)
+	conf1 := vppagent.Config(ctx1).GetVppConfig()
+	assert.Equal(t, uint32(1), conf1.GetInterfaces()[0].GetVrf())
+	assert.Equal(t, tables(1), conf1.GetVrfs())
+	require.Len(t, conf1.GetRoutes(), 1)
+	assert.Equal(t, uint32(1), conf1.GetRoutes()[0].GetVrfId())
+
+	ctx2 := vppagent.WithConfig(context.Background())
+	conn2, err := server.Request(ctx2, request("id2", "tenant2"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), vppagent.Config(ctx2).GetVppConfig().GetInterfaces()[0].GetVrf())
+	assert.Equal(t, tables(2), vppagent.Config(ctx2).GetVppConfig().GetVrfs())
+
+	// Connections of the same tenant share the vrf
+	ctx3 := vppagent.WithConfig(context.Background())
+	conn3, err := server.Request(ctx3, request("id3", "tenant1"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), vppagent.Config(ctx3).GetVppConfig().GetInterfaces()[0].GetVrf())
+
+	// Refreshes keep the vrf
+	ctx1 = vppagent.WithConfig(context.Background())
+	conn1, err = server.Request(ctx1, &networkservice.NetworkServiceRequest{Connection: conn1})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), vppagent.Config(ctx1).GetVppConfig().GetInterfaces()[0].GetVrf())
+
+	// The tables are only deleted with the last connection of the vrf
+	ctx1 = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx1, conn1)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), vppagent.Config(ctx1).GetVppConfig().GetInterfaces()[0].GetVrf())
+	assert.Empty(t, vppagent.Config(ctx1).GetVppConfig().GetVrfs())
+
+	ctx3 = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx3, conn3)
+	require.NoError(t, err)
+	assert.Equal(t, tables(1), vppagent.Config(ctx3).GetVppConfig().GetVrfs())
+
+	ctx2 = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx2, conn2)
+	require.NoError(t, err)
+	assert.Equal(t, tables(2), vppagent.Config(ctx2).GetVppConfig().GetVrfs())
+
+	// Freed vrf ids are reused
+	ctx1 = vppagent.WithConfig(context.Background())
+	_, err = server.Request(ctx1, request("id4", "tenant3"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), vppagent.Config(ctx1).GetVppConfig().GetInterfaces()[0].GetVrf())
+}
+
+func TestVrfServer_NetworkService(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(t.TempDir()),
+		vrf.NewServer(vrf.WithTables(vrf.NewTables(vrf.LabelKey))),
+	)
+	req := request("id", "")
+	req.GetConnection().Labels = nil
+
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := server.Request(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetVrf())
+
+	// Without a label or network service the connection stays in the default vrf
+	req = request("other", "")
+	req.GetConnection().NetworkService = ""
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = server.Request(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetVrf())
+	assert.Empty(t, vppagent.Config(ctx).GetVppConfig().GetVrfs())
+}
+
+func TestVrfServer_Rollback(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	tables := vrf.NewTables(vrf.LabelKey)
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(t.TempDir()),
+		vrf.NewServer(vrf.WithTables(tables)),
+	)
+	failing := chain.NewNetworkServiceServer(
+		memif.NewServer(t.TempDir()),
+		vrf.NewServer(vrf.WithTables(tables)),
+		injecterror.NewServer(),
+	)
+
+	// A failed request leaves no vrf behind
+	_, err := failing.Request(vppagent.WithConfig(context.Background()), request("id1", "tenant1"))
+	require.Error(t, err)
+	ctx := vppagent.WithConfig(context.Background())
+	conn, err := server.Request(ctx, request("id2", "tenant2"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetVrf())
+
+	// A failed refresh into another vrf, or a failed close, leaves the connection in its vrf
+	refresh := request("id2", "tenant3")
+	_, err = failing.Request(vppagent.WithConfig(context.Background()), refresh)
+	require.Error(t, err)
+	_, err = failing.Close(vppagent.WithConfig(context.Background()), conn)
+	require.Error(t, err)
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx, conn)
+	require.NoError(t, err)
+	assert.Equal(t, tables(1), vppagent.Config(ctx).GetVppConfig().GetVrfs())
+}
+
+func TestVrfServer_Refresh(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(t.TempDir()),
+		vrf.NewServer(vrf.WithTables(vrf.NewTables(vrf.LabelKey))),
+	)
+	_, err := server.Request(vppagent.WithConfig(context.Background()), request("id1", "tenant1"))
+	require.NoError(t, err)
+	_, err = server.Request(vppagent.WithConfig(context.Background()), request("id2", "tenant1"))
+	require.NoError(t, err)
+
+	// A refresh out of a vrf still in use by another connection leaves its tables
+	ctx := vppagent.WithConfig(context.Background())
+	_, err = server.Request(ctx, request("id1", "tenant2"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetVrf())
+	assert.Empty(t, vppagent.StaleConfig(ctx).GetVppConfig().GetVrfs())
+
+	// A refresh of the last connection out of a vrf deletes its tables
+	ctx = vppagent.WithConfig(context.Background())
+	conn, err := server.Request(ctx, request("id2", "tenant2"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetVrf())
+	assert.Equal(t, tables(1), vppagent.StaleConfig(ctx).GetVppConfig().GetVrfs())
+
+	// The freed vrf id is reused
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = server.Request(ctx, request("id3", "tenant3"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), vppagent.Config(ctx).GetVppConfig().GetInterfaces()[0].GetVrf())
+
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx, conn)
+	require.NoError(t, err)
+	assert.Empty(t, vppagent.StaleConfig(ctx).GetVppConfig().GetVrfs())
+}
+
+func TestVrfServer_DefaultTables(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server1 := chain.NewNetworkServiceServer(
+		memif.NewServer(t.TempDir()),
+		vrf.NewServer(),
+	)
+	server2 := chain.NewNetworkServiceServer(
+		memif.NewServer(t.TempDir()),
+		vrf.NewServer(),
+	)
+
+	// Chain elements without tables of their own still keep the tenants in different vrfs
+	ctx1 := vppagent.WithConfig(context.Background())
+	conn1, err := server1.Request(ctx1, request("id1", "tenant1"))
+	require.NoError(t, err)
+	ctx2 := vppagent.WithConfig(context.Background())
+	conn2, err := server2.Request(ctx2, request("id2", "tenant2"))
+	require.NoError(t, err)
+	assert.NotEqual(t, vppagent.Config(ctx1).GetVppConfig().GetInterfaces()[0].GetVrf(),
+		vppagent.Config(ctx2).GetVppConfig().GetInterfaces()[0].GetVrf())
+
+	_, err = server1.Close(vppagent.WithConfig(context.Background()), conn1)
+	require.NoError(t, err)
+	_, err = server2.Close(vppagent.WithConfig(context.Background()), conn2)
+	require.NoError(t, err)
+}
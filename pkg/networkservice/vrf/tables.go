@@ -0,0 +1,185 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vrf
+
+import (
+	"math"
+	"sync"
+
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vpp_l3 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l3"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+)
+
+// Tables - allocates the vrf ids for the tenants or network services, shared by the connections using them
+type Tables struct {
+	labelKey    string
+	executor    sync.Mutex
+	tables      map[string]*table
+	ids         map[uint32]bool
+	connections map[string]string
+}
+
+type table struct {
+	id    uint32
+	count int
+}
+
+// NewTables - returns a new Tables, with the vrf of a connection identified by the value of its labelKey label or
+//             its network service if it has no such label.  So without the label every network service gets a vrf of
+//             its own, and only connections with neither stay in the default vrf (0).
+func NewTables(labelKey string) *Tables {
+	return &Tables{
+		labelKey:    labelKey,
+		tables:      make(map[string]*table),
+		ids:         make(map[uint32]bool),
+		connections: make(map[string]string),
+	}
+}
+
+// load - returns the vrf id for conn, allocating it on the first connection of the vrf, along with undo putting back
+//        what conn was loaded into before.  staleID is the vrf conn is moved out of on refresh if conn was its last
+//        connection, 0 otherwise.
+func (t *Tables) load(conn *networkservice.Connection) (id, staleID uint32, undo func(), ok bool) {
+	name := t.name(conn)
+	if name == "" {
+		return 0, 0, nil, false
+	}
+	t.executor.Lock()
+	defer t.executor.Unlock()
+	connID := conn.GetId()
+	oldName, loaded := t.connections[connID]
+	if loaded && oldName == name {
+		return t.tables[name].id, 0, func() {}, true
+	}
+	var oldID uint32
+	if loaded {
+		oldID = t.tables[oldName].id
+		if t.release(connID) {
+			staleID = oldID
+		}
+	}
+	id = t.acquire(connID, name, 0)
+	return id, staleID, func() {
+		t.executor.Lock()
+		defer t.executor.Unlock()
+		if t.connections[connID] != name {
+			return
+		}
+		t.release(connID)
+		if loaded {
+			t.acquire(connID, oldName, oldID)
+		}
+	}, true
+}
+
+// unload - returns the vrf id of conn and releases it, last is true if conn was the last connection of the vrf.  undo
+//          loads conn back into the vrf.
+func (t *Tables) unload(conn *networkservice.Connection) (id uint32, last bool, undo func(), ok bool) {
+	t.executor.Lock()
+	defer t.executor.Unlock()
+	connID := conn.GetId()
+	name, loaded := t.connections[connID]
+	if !loaded {
+		return 0, false, nil, false
+	}
+	id = t.tables[name].id
+	return id, t.release(connID), func() {
+		t.executor.Lock()
+		defer t.executor.Unlock()
+		if _, reloaded := t.connections[connID]; !reloaded {
+			t.acquire(connID, name, id)
+		}
+	}, true
+}
+
+// acquire - adds connID to the vrf name, allocating it with id, or a free id if id is 0 or taken, if it has no
+//           connections yet
+func (t *Tables) acquire(connID, name string, id uint32) uint32 {
+	tbl, ok := t.tables[name]
+	if !ok {
+		if id == 0 || t.ids[id] {
+			id = t.freeID()
+		}
+		tbl = &table{id: id}
+		t.tables[name] = tbl
+		t.ids[id] = true
+	}
+	tbl.count++
+	t.connections[connID] = name
+	return tbl.id
+}
+
+func (t *Tables) release(connID string) (last bool) {
+	name := t.connections[connID]
+	delete(t.connections, connID)
+	tbl := t.tables[name]
+	tbl.count--
+	if tbl.count > 0 {
+		return false
+	}
+	delete(t.tables, name)
+	delete(t.ids, tbl.id)
+	return true
+}
+
+// freeID - returns the lowest vrf id in use by neither the default vrf (0) nor SRv6 (math.MaxUint32)
+func (t *Tables) freeID() uint32 {
+	var id uint32 = 1
+	for t.ids[id] && id < math.MaxUint32-1 {
+		id++
+	}
+	return id
+}
+
+func (t *Tables) name(conn *networkservice.Connection) string {
+	if name := conn.GetLabels()[t.labelKey]; name != "" {
+		return name
+	}
+	return conn.GetNetworkService()
+}
+
+// bind - binds the last vpp interface of conf to the vrf id and adds the IPv4 and IPv6 tables of the vrf to conf
+func bind(conf *configurator.Config, id uint32, withTables bool) {
+	index := len(conf.GetVppConfig().GetInterfaces()) - 1
+	if index < 0 {
+		return
+	}
+	conf.GetVppConfig().GetInterfaces()[index].Vrf = id
+	if withTables {
+		appendTables(conf, id)
+	}
+}
+
+// appendTables - adds the IPv4 and IPv6 tables of the vrf id to conf
+func appendTables(conf *configurator.Config, id uint32) {
+	if conf.GetVppConfig() == nil {
+		conf.VppConfig = &vpp.ConfigData{}
+	}
+	conf.GetVppConfig().Vrfs = append(conf.GetVppConfig().Vrfs,
+		&vpp_l3.VrfTable{
+			Id:       id,
+			Protocol: vpp_l3.VrfTable_IPV4,
+		},
+		&vpp_l3.VrfTable{
+			Id:       id,
+			Protocol: vpp_l3.VrfTable_IPV6,
+		},
+	)
+}
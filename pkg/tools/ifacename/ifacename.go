@@ -0,0 +1,177 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ifacename provides short, collision free names for the linux interfaces created on behalf of connections
+package ifacename
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+)
+
+const (
+	// minHashLength - the fewest hex digits of the hash a name may carry
+	minHashLength = 4
+	// maxSalt - the most times the hash of a connection ID is salted to get a free name
+	maxSalt = 64
+)
+
+// Names hands out linux interface names of the form <prefix>-<hash of the connection ID>, which fit in
+// kernel.LinuxIfMaxLength.  A name is the same for a connection ID every time, unless it collides with the name of
+// another connection, in which case the hash is salted until it doesn't.  Names only knows the names handed out by
+// this process, so the name a connection got is expected to travel with it and be Reserved again after a restart.
+type Names struct {
+	names map[string]string
+	ids   map[string]string
+	mu    sync.Mutex
+}
+
+// NewNames - returns an empty *Names
+func NewNames() *Names {
+	return &Names{
+		names: make(map[string]string),
+		ids:   make(map[string]string),
+	}
+}
+
+// Acquire - returns the name of the interface with prefix for connID, reserving it until Release(connID)
+func (n *Names) Acquire(prefix, connID string) (string, error) {
+	hashLength := kernel.LinuxIfMaxLength - len(prefix) - 1
+	if hashLength < minHashLength {
+		return "", errors.Errorf("interface name prefix %q is too long", prefix)
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if name, ok := n.ids[connID]; ok {
+		return name, nil
+	}
+	for salt := 0; salt < maxSalt; salt++ {
+		name := prefix + "-" + hash(connID, salt)[:hashLength]
+		if _, used := n.names[name]; !used {
+			n.names[name] = connID
+			n.ids[connID] = name
+			return name, nil
+		}
+	}
+	return "", errors.Errorf("no free interface name with prefix %q for connection %q", prefix, connID)
+}
+
+// AcquireInterfaceName - returns the name of the interface of conn in the network namespace of its kernel Mechanism.
+//                        That is the name the Mechanism sets, if it fits, or else the one it makes up, if that keeps
+//                        the whole connection ID.  Otherwise a name with prefix is Acquired and recorded in the
+//                        Mechanism, to be Reserved again on refresh and after a restart.
+func (n *Names) AcquireInterfaceName(prefix string, conn *networkservice.Connection) (string, error) {
+	mechanism := conn.GetMechanism()
+	name, ok := mechanism.GetParameters()[kernel.InterfaceNameKey]
+	switch {
+	case ok && Matches(prefix, conn.GetId(), name):
+		if err := n.Reserve(conn.GetId(), name); err != nil {
+			return "", err
+		}
+		return name, nil
+	case ok && name != "" && len(name) <= kernel.LinuxIfMaxLength:
+		return name, nil
+	case !ok:
+		// The name made up by the Mechanism is cut to fit, so it is only unique if the connection ID is left whole
+		if name = kernel.ToMechanism(mechanism).GetInterfaceName(conn); strings.HasSuffix(name, "-"+conn.GetId()) {
+			return name, nil
+		}
+	}
+	name, err := n.Acquire(prefix, conn.GetId())
+	if err != nil {
+		return "", err
+	}
+	if mechanism.GetParameters() == nil {
+		mechanism.Parameters = make(map[string]string)
+	}
+	mechanism.GetParameters()[kernel.InterfaceNameKey] = name
+	return name, nil
+}
+
+// Reserve - reserves name for connID, the name connID got from Acquire before, until Release(connID)
+func (n *Names) Reserve(connID, name string) error {
+	if name == "" || len(name) > kernel.LinuxIfMaxLength {
+		return errors.Errorf("invalid interface name %q", name)
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if owner, used := n.names[name]; used && owner != connID {
+		return errors.Errorf("interface name %q is already in use by connection %q", name, owner)
+	}
+	if current, ok := n.ids[connID]; ok && current != name {
+		delete(n.names, current)
+	}
+	n.names[name] = connID
+	n.ids[connID] = name
+	return nil
+}
+
+// Lookup - returns the connection ID name is given to
+func (n *Names) Lookup(name string) (connID string, ok bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	connID, ok = n.names[name]
+	return connID, ok
+}
+
+// Acquired - returns true if connID holds a name
+func (n *Names) Acquired(connID string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_, ok := n.ids[connID]
+	return ok
+}
+
+// Release - releases the name given to connID
+func (n *Names) Release(connID string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if name, ok := n.ids[connID]; ok {
+		delete(n.names, name)
+		delete(n.ids, connID)
+	}
+}
+
+// Matches - returns true if name is one Acquire(prefix, connID) may return, in any process
+func Matches(prefix, connID, name string) bool {
+	hashLength := kernel.LinuxIfMaxLength - len(prefix) - 1
+	if hashLength < minHashLength || len(name) != kernel.LinuxIfMaxLength || !strings.HasPrefix(name, prefix+"-") {
+		return false
+	}
+	for salt := 0; salt < maxSalt; salt++ {
+		if name[len(prefix)+1:] == hash(connID, salt)[:hashLength] {
+			return true
+		}
+	}
+	return false
+}
+
+func hash(connID string, salt int) string {
+	input := connID
+	if salt > 0 {
+		input += "#" + strconv.Itoa(salt)
+	}
+	sum := sha256.Sum256([]byte(input))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ifacename_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifacename"
+)
+
+// prefix leaves room for the shortest hash, so collisions are easy to come by
+const prefix = "0123456789"
+
+func TestNames(t *testing.T) {
+	names := ifacename.NewNames()
+	name, err := names.Acquire(prefix, "id1")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(name, prefix+"-"))
+	assert.LessOrEqual(t, len(name), kernel.LinuxIfMaxLength)
+
+	// The same connection ID gets the same name, in this process and any other
+	again, err := names.Acquire(prefix, "id1")
+	require.NoError(t, err)
+	assert.Equal(t, name, again)
+	other, err := ifacename.NewNames().Acquire(prefix, "id1")
+	require.NoError(t, err)
+	assert.Equal(t, name, other)
+
+	// The connection ID of a name is looked up, and a name is told apart from the names of other connections
+	connID, ok := names.Lookup(name)
+	assert.True(t, ok)
+	assert.Equal(t, "id1", connID)
+	_, ok = names.Lookup(prefix + "-unknown")
+	assert.False(t, ok)
+	assert.True(t, ifacename.Matches(prefix, "id1", name))
+	assert.False(t, ifacename.Matches(prefix, "id2", name))
+	assert.False(t, ifacename.Matches("other", "id1", name))
+
+	_, err = names.Acquire(prefix+"-too-long", "id1")
+	assert.NotNil(t, err)
+}
+
+func TestNamesAcquireInterfaceName(t *testing.T) {
+	names := ifacename.NewNames()
+	newConn := func(id string, parameters map[string]string) *networkservice.Connection {
+		return &networkservice.Connection{
+			Id:             id,
+			NetworkService: "network-service",
+			Mechanism: &networkservice.Mechanism{
+				Cls:        cls.LOCAL,
+				Type:       kernel.MECHANISM,
+				Parameters: parameters,
+			},
+		}
+	}
+
+	// A name set by the Mechanism is kept if it fits
+	name, err := names.AcquireInterfaceName(prefix, newConn("id1", map[string]string{kernel.InterfaceNameKey: "nsm-1"}))
+	require.NoError(t, err)
+	assert.Equal(t, "nsm-1", name)
+
+	// Long connection IDs sharing a prefix don't get the same name cut out of them...
+	conn1 := newConn("ConnectionIdWithALongPrefix-1", nil)
+	name1, err := names.AcquireInterfaceName(prefix, conn1)
+	require.NoError(t, err)
+	conn2 := newConn("ConnectionIdWithALongPrefix-2", nil)
+	name2, err := names.AcquireInterfaceName(prefix, conn2)
+	require.NoError(t, err)
+	assert.NotEqual(t, name1, name2)
+	assert.LessOrEqual(t, len(name1), kernel.LinuxIfMaxLength)
+	assert.LessOrEqual(t, len(name2), kernel.LinuxIfMaxLength)
+
+	// ... and the names they get are recorded, to be Reserved again after a restart
+	assert.Equal(t, name1, conn1.GetMechanism().GetParameters()[kernel.InterfaceNameKey])
+	restarted := ifacename.NewNames()
+	again, err := restarted.AcquireInterfaceName(prefix, conn1)
+	require.NoError(t, err)
+	assert.Equal(t, name1, again)
+	connID, ok := restarted.Lookup(name1)
+	assert.True(t, ok)
+	assert.Equal(t, conn1.GetId(), connID)
+}
+
+func TestNamesCollision(t *testing.T) {
+	// Find two connection IDs whose names collide
+	var first, second, collidingName string
+	seen := make(map[string]string)
+	for i := 0; i < 100000 && second == ""; i++ {
+		connID := fmt.Sprintf("id%d", i)
+		name, err := ifacename.NewNames().Acquire(prefix, connID)
+		require.NoError(t, err)
+		if owner, ok := seen[name]; ok {
+			first, second, collidingName = owner, connID, name
+		}
+		seen[name] = connID
+	}
+	require.NotEmpty(t, second)
+
+	// The second one gets a salted name
+	names := ifacename.NewNames()
+	name1, err := names.Acquire(prefix, first)
+	require.NoError(t, err)
+	assert.Equal(t, collidingName, name1)
+	name2, err := names.Acquire(prefix, second)
+	require.NoError(t, err)
+	assert.NotEqual(t, name1, name2)
+	assert.True(t, strings.HasPrefix(name2, prefix+"-"))
+	assert.LessOrEqual(t, len(name2), kernel.LinuxIfMaxLength)
+
+	// After a restart the names are Reserved as they were handed out, whatever the order of the connections
+	restarted := ifacename.NewNames()
+	require.NoError(t, restarted.Reserve(second, name2))
+	require.NoError(t, restarted.Reserve(first, name1))
+	assert.NotNil(t, restarted.Reserve("id", name1))
+
+	// A released name can be given out again
+	names.Release(first)
+	assert.False(t, names.Acquired(first))
+	require.NoError(t, names.Reserve("id", name1))
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ipaddrs provides helpers for the IpAddresses of vpp and linux interfaces
+package ipaddrs
+
+import (
+	"context"
+	"net"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// Append - appends addrs to the ipAddresses of an interface, keeping the ipAddresses set by others.  Left out, and
+//          logged unless empty or already there, are:
+//          - addresses already there
+//          - anything that is not an IP address, with or without its prefix length
+//          - IPv6 link-local addresses, as both the kernel and vpp configure their own on every IPv6 enabled
+//            interface, so they are never taken from the IP context
+//          Note: nothing is tracked per connection.  The addresses a previous Request added are only removed on refresh
+//          because the chain builds the interface config from scratch for every Request and Close, so vpp-agent
+//          replaces the interface with one that no longer has them.  Removing just the addresses of one connection
+//          from an interface kept by others is not supported, and neither are several addresses per side, as the
+//          IPContext has a single SrcIpAddr and DstIpAddr.
+func Append(ctx context.Context, ipAddresses []string, addrs ...string) []string {
+	for _, addr := range addrs {
+		ip := parseIP(addr)
+		switch {
+		case ip == nil:
+			if addr != "" {
+				log.Entry(ctx).Warnf("ignoring %q, not an IP address", addr)
+			}
+			continue
+		case ip.To4() == nil && ip.IsLinkLocalUnicast():
+			log.Entry(ctx).Warnf("ignoring IPv6 link-local address %q, the interface configures its own", addr)
+			continue
+		case contains(ipAddresses, addr):
+			continue
+		}
+		ipAddresses = append(ipAddresses, addr)
+	}
+	return ipAddresses
+}
+
+func parseIP(addr string) net.IP {
+	if ip, _, err := net.ParseCIDR(addr); err == nil {
+		return ip
+	}
+	return net.ParseIP(addr)
+}
+
+func contains(ipAddresses []string, addr string) bool {
+	for _, ipAddress := range ipAddresses {
+		if ipAddress == addr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipaddrs_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
+)
+
+func TestAppend(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	ipAddresses := []string{"10.0.0.1/24"}
+	ipAddresses = ipaddrs.Append(context.Background(), ipAddresses, "172.16.1.1/31", "fd00::1/127", "10.0.0.1/24", "fe80::1/64", "", "invalid", "1.1.1.1")
+	assert.Equal(t, []string{"10.0.0.1/24", "172.16.1.1/31", "fd00::1/127", "1.1.1.1"}, ipAddresses)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package macaddr provides stable mac addresses for the interfaces created on behalf of connections
+package macaddr
+
+import (
+	"crypto/sha256"
+	"net"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+)
+
+const (
+	// Src - side of the interface whose mac address is the SrcMac of the EthernetContext
+	Src = "src"
+	// Dst - side of the interface whose mac address is the DstMac of the EthernetContext
+	Dst = "dst"
+)
+
+// Generate - returns the locally administered unicast mac address of the interface on side of the connection connID,
+//            the same for a connection ID and side every time
+func Generate(connID, side string) string {
+	sum := sha256.Sum256([]byte(connID + "/" + side))
+	mac := net.HardwareAddr(sum[:6])
+	// Set the locally administered bit and clear the multicast bit
+	mac[0] = (mac[0] | 0x02) &^ 0x01
+	return mac.String()
+}
+
+// EthernetContext - returns the EthernetContext of conn, adding it, along with the ConnectionContext, if conn has none
+func EthernetContext(conn *networkservice.Connection) *networkservice.EthernetContext {
+	if conn.GetContext() == nil {
+		conn.Context = &networkservice.ConnectionContext{}
+	}
+	if conn.GetContext().GetEthernetContext() == nil {
+		conn.GetContext().EthernetContext = &networkservice.EthernetContext{}
+	}
+	return conn.GetContext().GetEthernetContext()
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package macaddr_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/macaddr"
+)
+
+func TestGenerate(t *testing.T) {
+	src := macaddr.Generate("id", macaddr.Src)
+	assert.Equal(t, src, macaddr.Generate("id", macaddr.Src))
+	assert.NotEqual(t, src, macaddr.Generate("id", macaddr.Dst))
+	assert.NotEqual(t, src, macaddr.Generate("other", macaddr.Src))
+
+	mac, err := net.ParseMAC(src)
+	require.NoError(t, err)
+	assert.Len(t, mac, 6)
+	assert.Equal(t, byte(0x02), mac[0]&0x02, "locally administered")
+	assert.Equal(t, byte(0x00), mac[0]&0x01, "unicast")
+}
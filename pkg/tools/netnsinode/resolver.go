@@ -0,0 +1,212 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package netnsinode
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	procDir = "/proc"
+)
+
+// PreferenceFunc - returns true if the process pid (found in procDir) should be preferred over others as the owner of
+//                  its network namespace
+type PreferenceFunc func(procDir, pid string) bool
+
+// PreferPause - PreferenceFunc preferring processes whose cmdline contains "pause", the pod sandbox processes
+func PreferPause(dir, pid string) bool {
+	data, err := ioutil.ReadFile(filepath.Join(dir, filepath.Clean(pid), "cmdline"))
+	return err == nil && strings.Contains(string(data), "pause")
+}
+
+type process struct {
+	inode     uint64
+	preferred bool
+}
+
+// Resolver resolves network namespace inodes to /proc/<pid>/ns/net files.  It keeps an inode -> pid index of the
+// processes it has seen, and only stats the network namespaces of processes that have appeared since it last looked
+// at /proc.  Indexed entries are validated before they are returned, so a process that exited or moved to another
+// namespace is only dropped from the namespace it was indexed in once that namespace is looked up.
+type Resolver struct {
+	procDir   string
+	prefer    PreferenceFunc
+	processes map[string]*process
+	members   map[uint64]map[string]bool
+	owners    map[uint64]string
+	mu        sync.Mutex
+}
+
+// Option - option for use with netnsinode.NewResolver(...)
+type Option func(r *Resolver)
+
+// WithProcDir - sets the directory processes are found in, the default is /proc
+func WithProcDir(dir string) Option {
+	return func(r *Resolver) {
+		r.procDir = dir
+	}
+}
+
+// WithPreference - sets the function choosing between processes sharing a network namespace, the default is PreferPause
+func WithPreference(prefer PreferenceFunc) Option {
+	return func(r *Resolver) {
+		r.prefer = prefer
+	}
+}
+
+// NewResolver - returns a new *Resolver
+func NewResolver(opts ...Option) *Resolver {
+	r := &Resolver{
+		procDir:   procDir,
+		prefer:    PreferPause,
+		processes: make(map[string]*process),
+		members:   make(map[uint64]map[string]bool),
+		owners:    make(map[uint64]string),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Resolve - returns the <procDir>/<pid>/ns/net file of the network namespace with inode
+func (r *Resolver) Resolve(inode uint64) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if filename, preferred, ok := r.lookup(inode); ok && preferred {
+		return filename, nil
+	}
+	// The namespace may be new, or have a preferred process by now
+	if err := r.refresh(); err != nil {
+		return "", err
+	}
+	if filename, _, ok := r.lookup(inode); ok {
+		return filename, nil
+	}
+	return "", errors.Errorf("%s/${pid}/ns/net with inode %d not found", r.procDir, inode)
+}
+
+// lookup - returns the indexed file for inode, if it is still the network namespace with inode, and whether its
+//          process is preferred.  The processes of inode that turn out to have exited or moved are dropped from it.
+func (r *Resolver) lookup(inode uint64) (filename string, preferred, ok bool) {
+	for {
+		pid, owned := r.owners[inode]
+		if !owned {
+			return "", false, false
+		}
+		filename = r.netNSFilename(pid)
+		current, err := getInode(filename)
+		if err == nil && current == inode {
+			return filename, r.processes[pid].preferred, true
+		}
+		r.forget(pid)
+		if err == nil {
+			// The process has moved to another namespace, or its pid was reused
+			r.index(pid, current)
+		}
+	}
+}
+
+// refresh - indexes the processes started since the last refresh, and forgets the ones that exited
+func (r *Resolver) refresh() error {
+	dir, err := os.Open(r.procDir)
+	if err != nil {
+		return errors.Wrapf(err, "can't read %s directory", r.procDir)
+	}
+	defer func() { _ = dir.Close() }()
+	pids, err := dir.Readdirnames(-1)
+	if err != nil {
+		return errors.Wrapf(err, "can't read %s directory", r.procDir)
+	}
+	seen := make(map[string]bool, len(pids))
+	for _, pid := range pids {
+		if !isDigits(pid) {
+			continue
+		}
+		seen[pid] = true
+		if _, ok := r.processes[pid]; ok {
+			continue
+		}
+		inode, statErr := getInode(r.netNSFilename(pid))
+		if statErr != nil {
+			// Remember the process anyway, so it is not stat'ed again on every refresh
+			r.processes[pid] = &process{}
+			continue
+		}
+		r.index(pid, inode)
+	}
+	for pid := range r.processes {
+		if !seen[pid] {
+			r.forget(pid)
+		}
+	}
+	return nil
+}
+
+// index - adds pid to the processes of the network namespace with inode, making it its owner if it is preferred over
+//         the current one
+func (r *Resolver) index(pid string, inode uint64) {
+	p := &process{
+		inode:     inode,
+		preferred: r.prefer(r.procDir, pid),
+	}
+	r.processes[pid] = p
+	if r.members[inode] == nil {
+		r.members[inode] = make(map[string]bool)
+	}
+	r.members[inode][pid] = true
+	if owner, ok := r.owners[inode]; !ok || (p.preferred && !r.processes[owner].preferred) {
+		r.owners[inode] = pid
+	}
+}
+
+// forget - removes pid from the index, electing another owner of its network namespace if it owned it
+func (r *Resolver) forget(pid string) {
+	p, ok := r.processes[pid]
+	if !ok {
+		return
+	}
+	delete(r.processes, pid)
+	if members, ok := r.members[p.inode]; ok {
+		delete(members, pid)
+		if len(members) == 0 {
+			delete(r.members, p.inode)
+		}
+	}
+	if owner, ok := r.owners[p.inode]; !ok || owner != pid {
+		return
+	}
+	delete(r.owners, p.inode)
+	for otherPid := range r.members[p.inode] {
+		if owner, ok := r.owners[p.inode]; !ok || (r.processes[otherPid].preferred && !r.processes[owner].preferred) {
+			r.owners[p.inode] = otherPid
+		}
+	}
+}
+
+func (r *Resolver) netNSFilename(pid string) string {
+	return filepath.Join(r.procDir, pid, "ns", "net")
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2020 Cisco Systems, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package netnsinode_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsinode"
+)
+
+// addProcess - adds a fake <procDir>/<pid> sharing the network namespace of sharePid, or with a new one if sharePid is ""
+func addProcess(t *testing.T, procDir, pid, sharePid, cmdline string) string {
+	filename := filepath.Join(procDir, pid, "ns", "net")
+	require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0700))
+	if sharePid != "" {
+		require.NoError(t, os.Link(filepath.Join(procDir, sharePid, "ns", "net"), filename))
+	} else {
+		require.NoError(t, ioutil.WriteFile(filename, nil, 0600))
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(procDir, pid, "cmdline"), []byte(cmdline), 0600))
+	return filename
+}
+
+func inode(t *testing.T, filename string) uint64 {
+	fileinfo, err := os.Stat(filename)
+	require.NoError(t, err)
+	return fileinfo.Sys().(*syscall.Stat_t).Ino
+}
+
+func TestResolver(t *testing.T) {
+	procDir := t.TempDir()
+	podNetNS := inode(t, addProcess(t, procDir, "100", "", "nginx"))
+	addProcess(t, procDir, "200", "100", "/pause")
+	addProcess(t, procDir, "300", "100", "sh")
+	otherNetNS := inode(t, addProcess(t, procDir, "400", "", "sh"))
+
+	r := netnsinode.NewResolver(netnsinode.WithProcDir(procDir))
+	filename, err := r.Resolve(podNetNS)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(procDir, "200", "ns", "net"), filename)
+	filename, err = r.Resolve(otherNetNS)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(procDir, "400", "ns", "net"), filename)
+
+	// The pause process exits, one of the others sharing its namespace takes over
+	require.NoError(t, os.RemoveAll(filepath.Join(procDir, "200")))
+	filename, err = r.Resolve(podNetNS)
+	require.NoError(t, err)
+	assert.Contains(t, []string{filepath.Join(procDir, "100", "ns", "net"), filepath.Join(procDir, "300", "ns", "net")}, filename)
+
+	// Processes started after the last refresh are found
+	newNetNS := inode(t, addProcess(t, procDir, "500", "", "sh"))
+	filename, err = r.Resolve(newNetNS)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(procDir, "500", "ns", "net"), filename)
+
+	// Namespaces whose processes have all exited are no longer found
+	require.NoError(t, os.RemoveAll(filepath.Join(procDir, "400")))
+	_, err = r.Resolve(otherNetNS)
+	assert.NotNil(t, err)
+}
+
+func TestResolverWithPreference(t *testing.T) {
+	procDir := t.TempDir()
+	netNS := inode(t, addProcess(t, procDir, "100", "", "/pause"))
+	addProcess(t, procDir, "200", "100", "envoy")
+
+	r := netnsinode.NewResolver(
+		netnsinode.WithProcDir(procDir),
+		netnsinode.WithPreference(func(dir, pid string) bool {
+			data, err := ioutil.ReadFile(filepath.Join(dir, pid, "cmdline"))
+			return err == nil && string(data) == "envoy"
+		}),
+	)
+	filename, err := r.Resolve(netNS)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(procDir, "200", "ns", "net"), filename)
+}
+
+func TestResolverChangedNetNS(t *testing.T) {
+	procDir := t.TempDir()
+	oldNetNS := inode(t, addProcess(t, procDir, "100", "", "sh"))
+
+	r := netnsinode.NewResolver(netnsinode.WithProcDir(procDir))
+	filename, err := r.Resolve(oldNetNS)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(procDir, "100", "ns", "net"), filename)
+
+	// The process moves to another namespace, or its pid is reused, after it was indexed: it is indexed in the new
+	// namespace once the old one is looked up
+	newFilename := filepath.Join(procDir, "100", "ns", "net.new")
+	require.NoError(t, ioutil.WriteFile(newFilename, nil, 0600))
+	newNetNS := inode(t, newFilename)
+	require.NoError(t, os.Rename(newFilename, filename))
+	_, err = r.Resolve(oldNetNS)
+	assert.NotNil(t, err)
+	filename, err = r.Resolve(newNetNS)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(procDir, "100", "ns", "net"), filename)
+}
+
+func TestResolverIncremental(t *testing.T) {
+	procDir := t.TempDir()
+	netNS := inode(t, addProcess(t, procDir, "100", "", "nginx"))
+	otherNetNS := inode(t, addProcess(t, procDir, "200", "", "/pause"))
+
+	// The preference is asked for once per process, when it is indexed
+	asked := make(map[string]int)
+	r := netnsinode.NewResolver(
+		netnsinode.WithProcDir(procDir),
+		netnsinode.WithPreference(func(dir, pid string) bool {
+			asked[pid]++
+			return netnsinode.PreferPause(dir, pid)
+		}),
+	)
+	filename, err := r.Resolve(netNS)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(procDir, "100", "ns", "net"), filename)
+	_, err = r.Resolve(otherNetNS)
+	require.NoError(t, err)
+	newNetNS := inode(t, addProcess(t, procDir, "300", "", "sh"))
+	_, err = r.Resolve(newNetNS)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"100": 1, "200": 1, "300": 1}, asked)
+
+	// A pause process started after the namespace was indexed takes it over
+	addProcess(t, procDir, "400", "100", "/pause")
+	filename, err = r.Resolve(netNS)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(procDir, "400", "ns", "net"), filename)
+}
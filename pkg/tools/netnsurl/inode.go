@@ -0,0 +1,38 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package netnsurl
+
+import (
+	"net/url"
+
+	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsinode"
+)
+
+func resolveInode(u *url.URL) (*linuxnamespace.NetNamespace, error) {
+	filename, err := netnsinode.LinuxNetNSFileName(value(u))
+	if err != nil {
+		return nil, err
+	}
+	return &linuxnamespace.NetNamespace{
+		Type:      linuxnamespace.NetNamespace_FD,
+		Reference: filename,
+	}, nil
+}
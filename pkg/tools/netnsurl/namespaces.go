@@ -0,0 +1,78 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netnsurl
+
+import (
+	"sync"
+
+	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
+)
+
+// Namespaces remembers the network namespace the NetNSURL of each connection was resolved to.  A NetNSURL may no
+// longer resolve by the time the connection is closed, as an inode:// one doesn't once the pod is gone, while the
+// namespace it was resolved to still names the same vppagent config.
+type Namespaces struct {
+	resolver *Resolver
+	byID     map[string]*linuxnamespace.NetNamespace
+	mu       sync.Mutex
+}
+
+// NewNamespaces - returns an empty *Namespaces resolving NetNSURLs with resolver
+func NewNamespaces(resolver *Resolver) *Namespaces {
+	return &Namespaces{
+		resolver: resolver,
+		byID:     make(map[string]*linuxnamespace.NetNamespace),
+	}
+}
+
+// Resolve - resolves netNSURL, remembering the network namespace for connID until Delete(connID)
+func (n *Namespaces) Resolve(connID, netNSURL string) (*linuxnamespace.NetNamespace, error) {
+	netNS, err := n.resolver.Resolve(netNSURL)
+	if err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.byID[connID] = netNS
+	return netNS, nil
+}
+
+// Load - returns the network namespace remembered for connID, resolving netNSURL if there is none
+func (n *Namespaces) Load(connID, netNSURL string) (*linuxnamespace.NetNamespace, error) {
+	n.mu.Lock()
+	netNS, ok := n.byID[connID]
+	n.mu.Unlock()
+	if ok {
+		return netNS, nil
+	}
+	return n.resolver.Resolve(netNSURL)
+}
+
+// Loaded - returns true if a network namespace is remembered for connID
+func (n *Namespaces) Loaded(connID string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_, ok := n.byID[connID]
+	return ok
+}
+
+// Delete - forgets the network namespace remembered for connID
+func (n *Namespaces) Delete(connID string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.byID, connID)
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package netnsurl resolves the NetNSURLs of kernel Mechanisms to the vppagent network namespaces they refer to
+package netnsurl
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
+)
+
+const (
+	// FileScheme - file:///proc/<pid>/ns/net, a file (typically a bind mount or a /proc entry) of a network namespace
+	FileScheme = "file"
+	// InodeScheme - inode://<inode>, the network namespace with inode number <inode>
+	InodeScheme = "inode"
+	// NetNSScheme - netns://<name>, the named network namespace /var/run/netns/<name>
+	NetNSScheme = "netns"
+	// PIDScheme - pid://<pid>, the network namespace of the process <pid>
+	PIDScheme = "pid"
+)
+
+// SchemeFunc - resolves a NetNSURL of a particular scheme to the network namespace it refers to
+type SchemeFunc func(netNSURL *url.URL) (*linuxnamespace.NetNamespace, error)
+
+// Resolver resolves NetNSURLs by their scheme
+type Resolver struct {
+	schemes map[string]SchemeFunc
+}
+
+// Option - option for use with netnsurl.NewResolver(...)
+type Option func(r *Resolver)
+
+// WithScheme - adds (or replaces) the SchemeFunc used to resolve NetNSURLs of scheme
+func WithScheme(scheme string, schemeFunc SchemeFunc) Option {
+	return func(r *Resolver) {
+		r.schemes[scheme] = schemeFunc
+	}
+}
+
+// NewResolver - returns a *Resolver for the file, inode, netns and pid schemes
+func NewResolver(opts ...Option) *Resolver {
+	r := &Resolver{
+		schemes: map[string]SchemeFunc{
+			FileScheme:  resolveFile,
+			InodeScheme: resolveInode,
+			NetNSScheme: resolveNetNS,
+			PIDScheme:   resolvePID,
+		},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Resolve - returns the network namespace netNSURL refers to
+func (r *Resolver) Resolve(netNSURL string) (*linuxnamespace.NetNamespace, error) {
+	u, err := url.Parse(netNSURL)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	schemeFunc, ok := r.schemes[u.Scheme]
+	if !ok {
+		return nil, errors.Errorf("NetNSURL scheme %q is not supported: %q", u.Scheme, netNSURL)
+	}
+	return schemeFunc(u)
+}
+
+// value - returns the part of inode://<value>, pid:<value> and the like after the scheme
+func value(u *url.URL) string {
+	if u.Opaque != "" {
+		return u.Opaque
+	}
+	return u.Host + u.Path
+}
+
+func resolveFile(u *url.URL) (*linuxnamespace.NetNamespace, error) {
+	if u.Path == "" {
+		return nil, errors.Errorf("NetNSURL has no path: %q", u)
+	}
+	return &linuxnamespace.NetNamespace{
+		Type:      linuxnamespace.NetNamespace_FD,
+		Reference: u.Path,
+	}, nil
+}
+
+func resolveNetNS(u *url.URL) (*linuxnamespace.NetNamespace, error) {
+	name := value(u)
+	if name == "" || strings.Contains(name, "/") {
+		return nil, errors.Errorf("NetNSURL must name a network namespace: %q", u)
+	}
+	return &linuxnamespace.NetNamespace{
+		Type:      linuxnamespace.NetNamespace_NSID,
+		Reference: name,
+	}, nil
+}
+
+func resolvePID(u *url.URL) (*linuxnamespace.NetNamespace, error) {
+	pid := value(u)
+	if _, err := strconv.ParseUint(pid, 10, 32); err != nil {
+		return nil, errors.Errorf("NetNSURL must carry a pid: %q", u)
+	}
+	return &linuxnamespace.NetNamespace{
+		Type:      linuxnamespace.NetNamespace_PID,
+		Reference: pid,
+	}, nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package netnsurl_test
+
+import (
+	"net/url"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsinode"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsurl"
+)
+
+func TestResolveFile(t *testing.T) {
+	netNS, err := netnsurl.NewResolver().Resolve("file:///proc/12/ns/net")
+	require.NoError(t, err)
+	assert.Equal(t, linuxnamespace.NetNamespace_FD, netNS.GetType())
+	assert.Equal(t, "/proc/12/ns/net", netNS.GetReference())
+
+	_, err = netnsurl.NewResolver().Resolve("file:")
+	assert.NotNil(t, err)
+}
+
+func TestResolveInode(t *testing.T) {
+	inode, err := netnsinode.GetMyNetNSInodeNum()
+	require.NoError(t, err)
+	netNS, err := netnsurl.NewResolver().Resolve((&url.URL{Scheme: netnsurl.InodeScheme, Host: strconv.FormatUint(inode, 10)}).String())
+	require.NoError(t, err)
+	assert.Equal(t, linuxnamespace.NetNamespace_FD, netNS.GetType())
+	fileinfo, err := os.Stat(netNS.GetReference())
+	require.NoError(t, err)
+	assert.Equal(t, inode, fileinfo.Sys().(*syscall.Stat_t).Ino)
+
+	_, err = netnsurl.NewResolver().Resolve("inode://not-an-inode")
+	assert.NotNil(t, err)
+	_, err = netnsurl.NewResolver().Resolve("inode://0")
+	assert.NotNil(t, err)
+}
+
+func TestResolveErrors(t *testing.T) {
+	for _, netNSURL := range []string{
+		"unknown:///proc/12/ns/net",
+		"netns://",
+		"pid://not-a-pid",
+		"%zz",
+	} {
+		_, err := netnsurl.NewResolver().Resolve(netNSURL)
+		assert.NotNil(t, err, netNSURL)
+	}
+}
+
+func TestNamespaces(t *testing.T) {
+	var gone bool
+	resolver := netnsurl.NewResolver(netnsurl.WithScheme(netnsurl.InodeScheme, func(u *url.URL) (*linuxnamespace.NetNamespace, error) {
+		if gone {
+			return nil, errors.Errorf("no network namespace with inode %s", u.Host)
+		}
+		return &linuxnamespace.NetNamespace{
+			Type:      linuxnamespace.NetNamespace_FD,
+			Reference: "/proc/12/ns/net",
+		}, nil
+	}))
+	namespaces := netnsurl.NewNamespaces(resolver)
+	netNS, err := namespaces.Resolve("id", "inode://12345")
+	require.NoError(t, err)
+	assert.True(t, namespaces.Loaded("id"))
+
+	// Once the pod is gone its NetNSURL doesn't resolve any more, but the namespace it was resolved to is remembered
+	gone = true
+	loaded, err := namespaces.Load("id", "inode://12345")
+	require.NoError(t, err)
+	assert.Equal(t, netNS, loaded)
+	_, err = namespaces.Resolve("id", "inode://12345")
+	assert.NotNil(t, err)
+
+	namespaces.Delete("id")
+	assert.False(t, namespaces.Loaded("id"))
+	_, err = namespaces.Load("id", "inode://12345")
+	assert.NotNil(t, err)
+}
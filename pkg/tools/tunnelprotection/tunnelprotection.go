@@ -0,0 +1,224 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tunnelprotection provides helpers for protecting the tunnel interfaces created by remote mechanisms with
+// vpp's IPsec tunnel protection.  Keys are generated per connection by the client and exchanged through the
+// Mechanism.Parameters.
+package tunnelprotection
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	vppipsec "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/ipsec"
+)
+
+const (
+	// SrcSPI - Mechanism.Parameters key for the SPI of the SA carrying traffic from the client to the server
+	SrcSPI = "ipsec_src_spi"
+	// SrcCryptoKey - Mechanism.Parameters key for the hex encoded crypto key of the SA carrying traffic from the client to the server
+	SrcCryptoKey = "ipsec_src_crypto_key"
+	// SrcIntegKey - Mechanism.Parameters key for the hex encoded integrity key of the SA carrying traffic from the client to the server
+	SrcIntegKey = "ipsec_src_integ_key"
+	// DstSPI - Mechanism.Parameters key for the SPI of the SA carrying traffic from the server to the client
+	DstSPI = "ipsec_dst_spi"
+	// DstCryptoKey - Mechanism.Parameters key for the hex encoded crypto key of the SA carrying traffic from the server to the client
+	DstCryptoKey = "ipsec_dst_crypto_key"
+	// DstIntegKey - Mechanism.Parameters key for the hex encoded integrity key of the SA carrying traffic from the server to the client
+	DstIntegKey = "ipsec_dst_integ_key"
+
+	cryptoAlg = vppipsec.CryptoAlg_AES_CBC_256
+	integAlg  = vppipsec.IntegAlg_SHA_256_128
+	// AES-CBC-256 and HMAC-SHA-256-128 both use 256 bit keys
+	keyLength = 32
+	// SPIs 1-255 are reserved by IANA
+	minSPI = 256
+)
+
+// indexes - the vpp SA indexes in use by the tunnels of this forwarder
+var indexes = &indexAllocator{
+	saIndexes: make(map[string][2]uint32),
+	used:      make(map[uint32]bool),
+}
+
+type indexAllocator struct {
+	saIndexes map[string][2]uint32
+	used      map[uint32]bool
+	mu        sync.Mutex
+}
+
+// Enabled - returns true if the parameters carry IPsec SAs for the tunnel
+func Enabled(parameters map[string]string) bool {
+	_, ok := parameters[SrcSPI]
+	return ok
+}
+
+// Generate - fills parameters with the SPIs and keys for a connection.  Any SPIs and keys already present in
+// previous (the parameters of the Mechanism the connection was using) are reused, so refreshes keep their SAs.
+func Generate(parameters, previous map[string]string) error {
+	if Enabled(previous) {
+		for _, key := range []string{SrcSPI, SrcCryptoKey, SrcIntegKey, DstSPI, DstCryptoKey, DstIntegKey} {
+			parameters[key] = previous[key]
+		}
+		return nil
+	}
+	for _, spiKey := range []string{SrcSPI, DstSPI} {
+		spi, err := randomSPI()
+		if err != nil {
+			return err
+		}
+		parameters[spiKey] = strconv.FormatUint(uint64(spi), 10)
+	}
+	for _, keyKey := range []string{SrcCryptoKey, SrcIntegKey, DstCryptoKey, DstIntegKey} {
+		key, err := randomKey()
+		if err != nil {
+			return err
+		}
+		parameters[keyKey] = key
+	}
+	return nil
+}
+
+// AppendConfig - appends the SAs found in parameters and the tunnel protection of ifaceName by them to conf.
+//                The SAs keep their vpp indexes until Release(ifaceName).
+//                isClient - whether we are the client side of the connection, and so send using the Src SA
+//                isClose - whether conf is to delete the SAs, in which case no vpp indexes are allocated: if the SAs
+//                          of ifaceName hold none (e.g. after a restart), nothing is appended
+func AppendConfig(conf *configurator.Config, ifaceName string, parameters map[string]string, isClient, isClose bool) error {
+	srcSA, err := securityAssociation(parameters, SrcSPI, SrcCryptoKey, SrcIntegKey)
+	if err != nil {
+		return err
+	}
+	dstSA, err := securityAssociation(parameters, DstSPI, DstCryptoKey, DstIntegKey)
+	if err != nil {
+		return err
+	}
+	outSA, inSA := srcSA, dstSA
+	if !isClient {
+		outSA, inSA = dstSA, srcSA
+	}
+	if isClose {
+		var ok bool
+		if outSA.Index, inSA.Index, ok = indexes.lookup(ifaceName); !ok {
+			return nil
+		}
+	} else {
+		outSA.Index, inSA.Index = indexes.acquire(ifaceName)
+	}
+	conf.GetVppConfig().IpsecSas = append(conf.GetVppConfig().IpsecSas, outSA, inSA)
+	conf.GetVppConfig().IpsecTunnelProtections = append(conf.GetVppConfig().IpsecTunnelProtections, &vppipsec.TunnelProtection{
+		Interface: ifaceName,
+		SaOut:     []uint32{outSA.GetIndex()},
+		SaIn:      []uint32{inSA.GetIndex()},
+	})
+	return nil
+}
+
+func securityAssociation(parameters map[string]string, spiKey, cryptoKeyKey, integKeyKey string) (*vppipsec.SecurityAssociation, error) {
+	spi, err := strconv.ParseUint(parameters[spiKey], 10, 32)
+	if err != nil || spi < minSPI {
+		return nil, errors.Errorf("ipsec parameter %s has wrong value: %q", spiKey, parameters[spiKey])
+	}
+	for _, key := range []string{cryptoKeyKey, integKeyKey} {
+		if decoded, decodeErr := hex.DecodeString(parameters[key]); decodeErr != nil || len(decoded) != keyLength {
+			return nil, errors.Errorf("ipsec parameter %s must be a hex encoded %d byte key", key, keyLength)
+		}
+	}
+	return &vppipsec.SecurityAssociation{
+		Spi:           uint32(spi),
+		Protocol:      vppipsec.SecurityAssociation_ESP,
+		CryptoAlg:     cryptoAlg,
+		CryptoKey:     parameters[cryptoKeyKey],
+		IntegAlg:      integAlg,
+		IntegKey:      parameters[integKeyKey],
+		UseAntiReplay: true,
+	}, nil
+}
+
+// Acquired - returns true if the SAs of ifaceName hold vpp indexes
+func Acquired(ifaceName string) bool {
+	return indexes.acquired(ifaceName)
+}
+
+// Release - releases the vpp indexes of the SAs of ifaceName
+func Release(ifaceName string) {
+	indexes.release(ifaceName)
+}
+
+func (a *indexAllocator) acquire(ifaceName string) (outIndex, inIndex uint32) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if saIndexes, ok := a.saIndexes[ifaceName]; ok {
+		return saIndexes[0], saIndexes[1]
+	}
+	var saIndexes [2]uint32
+	for i := range saIndexes {
+		for a.used[saIndexes[i]] {
+			saIndexes[i]++
+		}
+		a.used[saIndexes[i]] = true
+	}
+	a.saIndexes[ifaceName] = saIndexes
+	return saIndexes[0], saIndexes[1]
+}
+
+func (a *indexAllocator) lookup(ifaceName string) (outIndex, inIndex uint32, ok bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	saIndexes, ok := a.saIndexes[ifaceName]
+	return saIndexes[0], saIndexes[1], ok
+}
+
+func (a *indexAllocator) acquired(ifaceName string) bool {
+	_, _, ok := a.lookup(ifaceName)
+	return ok
+}
+
+func (a *indexAllocator) release(ifaceName string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if saIndexes, ok := a.saIndexes[ifaceName]; ok {
+		for _, index := range saIndexes {
+			delete(a.used, index)
+		}
+		delete(a.saIndexes, ifaceName)
+	}
+}
+
+func randomSPI() (uint32, error) {
+	b := make([]byte, 4)
+	for {
+		if _, err := rand.Read(b); err != nil {
+			return 0, errors.Wrap(err, "failed to generate ipsec SPI")
+		}
+		if spi := binary.BigEndian.Uint32(b); spi >= minSPI {
+			return spi, nil
+		}
+	}
+}
+
+func randomKey() (string, error) {
+	b := make([]byte, keyLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate ipsec key")
+	}
+	return hex.EncodeToString(b), nil
+}
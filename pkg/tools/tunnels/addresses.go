@@ -0,0 +1,128 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tunnels provides bookkeeping for tunnel interfaces that vpp can only tell apart by their src/dst IPs
+package tunnels
+
+import (
+	"net"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+)
+
+// Addresses hands out the IPs of a network routed to this node, one per connection.  Tunnels like GRE and IPIP carry
+// no key (the way vxlan carries a VNI) in vppagent, so vpp can only tell apart the tunnels between two nodes if each of
+// them originates from an IP of its own.
+type Addresses struct {
+	ipNet *net.IPNet
+	ips   map[string]net.IP
+	used  map[string]bool
+	mu    sync.Mutex
+}
+
+// NewAddresses - returns an empty *Addresses handing out the IPs of ipNet
+func NewAddresses(ipNet *net.IPNet) *Addresses {
+	network := &net.IPNet{IP: ipNet.IP.Mask(ipNet.Mask), Mask: ipNet.Mask}
+	if ipv4 := network.IP.To4(); ipv4 != nil && len(network.Mask) == net.IPv4len {
+		network.IP = ipv4
+	}
+	return &Addresses{
+		ipNet: network,
+		ips:   make(map[string]net.IP),
+		used:  make(map[string]bool),
+	}
+}
+
+// Acquire - returns the IP for connID, reserving it until Release(connID)
+func (a *Addresses) Acquire(connID string) (net.IP, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if ip, ok := a.ips[connID]; ok {
+		return ip, nil
+	}
+	for ip := a.ipNet.IP; ip != nil && a.ipNet.Contains(ip); ip = nextIP(ip) {
+		if a.used[ip.String()] || a.reserved(ip) {
+			continue
+		}
+		a.used[ip.String()] = true
+		a.ips[connID] = ip
+		return ip, nil
+	}
+	return nil, errors.Errorf("no free IP left in %s", a.ipNet)
+}
+
+// Lookup - returns the IP reserved for connID, if any
+func (a *Addresses) Lookup(connID string) (net.IP, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ip, ok := a.ips[connID]
+	return ip, ok
+}
+
+// Release - releases the IP reserved for connID
+func (a *Addresses) Release(connID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if ip, ok := a.ips[connID]; ok {
+		delete(a.used, ip.String())
+		delete(a.ips, connID)
+	}
+}
+
+// reserved - returns true for the network (and for IPv4 the broadcast) address of networks with room for hosts
+func (a *Addresses) reserved(ip net.IP) bool {
+	ones, bits := a.ipNet.Mask.Size()
+	if bits-ones < 2 {
+		return false
+	}
+	if ip.Equal(a.ipNet.IP) {
+		return true
+	}
+	next := nextIP(ip)
+	return bits == 8*net.IPv4len && (next == nil || !a.ipNet.Contains(next))
+}
+
+// nextIP - returns the IP following ip, nil if ip is the last one
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			return next
+		}
+	}
+	return nil
+}
+
+// AppendLocalAddress - appends a vpp loopback interface named name with ip as its address, so vpp takes in the tunnel
+//                      packets sent to ip
+func AppendLocalAddress(conf *configurator.Config, name string, ip net.IP) {
+	bits := 8 * net.IPv6len
+	if ip.To4() != nil {
+		ip, bits = ip.To4(), 8*net.IPv4len
+	}
+	conf.GetVppConfig().Interfaces = append(conf.GetVppConfig().Interfaces, &vpp.Interface{
+		Name:        name,
+		Type:        vppinterfaces.Interface_SOFTWARE_LOOPBACK,
+		Enabled:     true,
+		IpAddresses: []string{(&net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}).String()},
+	})
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tunnels_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/tunnels"
+)
+
+func TestAddresses(t *testing.T) {
+	_, ipNet, err := net.ParseCIDR("10.0.0.0/30")
+	require.NoError(t, err)
+	addresses := tunnels.NewAddresses(ipNet)
+
+	// The network and broadcast addresses are left out
+	ip1, err := addresses.Acquire("id1")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", ip1.String())
+	ip2, err := addresses.Acquire("id2")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", ip2.String())
+	_, err = addresses.Acquire("id3")
+	assert.Error(t, err)
+
+	// A connection keeps its IP until it is released
+	ip, err := addresses.Acquire("id1")
+	require.NoError(t, err)
+	assert.Equal(t, ip1, ip)
+	ip, ok := addresses.Lookup("id1")
+	assert.True(t, ok)
+	assert.Equal(t, ip1, ip)
+
+	addresses.Release("id1")
+	_, ok = addresses.Lookup("id1")
+	assert.False(t, ok)
+	ip, err = addresses.Acquire("id3")
+	require.NoError(t, err)
+	assert.Equal(t, ip1, ip)
+}
+
+func TestAddresses_HostPrefix(t *testing.T) {
+	_, ipNet, err := net.ParseCIDR("fd00::5/128")
+	require.NoError(t, err)
+	addresses := tunnels.NewAddresses(ipNet)
+
+	ip, err := addresses.Acquire("id1")
+	require.NoError(t, err)
+	assert.Equal(t, "fd00::5", ip.String())
+	_, err = addresses.Acquire("id2")
+	assert.Error(t, err)
+}